@@ -5,6 +5,7 @@ package main
 import (
 	"image"
 	_ "image/png"
+	"math"
 	"runtime"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
@@ -14,9 +15,6 @@ import (
 	"github.com/krapulacoders/krapulaengine2/windows"
 )
 
-const windowWidth = 800
-const windowHeight = 600
-
 func init() {
 	// GLFW event handling must run on the main OS thread
 	runtime.LockOSThread()
@@ -30,6 +28,22 @@ func main() {
 		panic(err.Error())
 	}
 
+	actions := windows.Actions()
+	actions.Bind("exit", windows.KeyBinding(glfw.KeyEscape, 0))
+	actions.Bind("toggle_text", windows.KeyBinding(glfw.KeySpace, 0))
+	actions.BindAxis("rotate", windows.AxisBinding{
+		Negative: windows.KeyBinding(glfw.KeyLeft, 0),
+		Positive: windows.KeyBinding(glfw.KeyRight, 0),
+	})
+	actions.BindAxis("orbit", windows.AxisBinding{
+		Negative: windows.KeyBinding(glfw.KeyA, 0),
+		Positive: windows.KeyBinding(glfw.KeyD, 0),
+	})
+	actions.BindAxis("tilt", windows.AxisBinding{
+		Negative: windows.KeyBinding(glfw.KeyS, 0),
+		Positive: windows.KeyBinding(glfw.KeyW, 0),
+	})
+
 	scene := newCubeScene(img)
 	windows.Init()
 	windows.AddScene("cube", scene)
@@ -40,15 +54,18 @@ func main() {
 
 var vertexShader = `
 #version 330
-uniform mat4 projection;
-uniform mat4 camera;
+layout(std140) uniform Camera {
+    mat4 view;
+    mat4 projection;
+    vec4 cameraPosition;
+};
 uniform mat4 model;
 in vec3 vert;
 in vec2 vertTexCoord;
 out vec2 fragTexCoord;
 void main() {
     fragTexCoord = vertTexCoord;
-    gl_Position = projection * camera * model * vec4(vert, 1);
+    gl_Position = projection * view * model * vec4(vert, 1);
 }
 ` + "\x00"
 
@@ -117,6 +134,8 @@ type cubeScene struct {
 	windows.SimpleSceneImpl
 
 	angle                     float32
+	camera                    *graphics.Camera3D
+	orbit                     *graphics.OrbitController
 	model                     mgl32.Mat4
 	program                   uint32
 	modelUniform              int32
@@ -136,12 +155,10 @@ func newCubeScene(img *image.RGBA) *cubeScene {
 }
 
 func (s *cubeScene) Tick(timedelta float64, keyStates []bool) {
-	if keyStates[glfw.KeyLeft] {
-		s.angle -= float32(timedelta)
-	}
-	if keyStates[glfw.KeyRight] {
-		s.angle += float32(timedelta)
-	}
+	actions := windows.Actions()
+	s.angle += float32(timedelta) * actions.Axis("rotate")
+	s.orbit.Rotate(float32(timedelta)*actions.Axis("orbit"), float32(timedelta)*actions.Axis("tilt"))
+	s.orbit.Apply(s.camera)
 }
 
 func (s *cubeScene) Render() {
@@ -165,16 +182,12 @@ func (s *cubeScene) Render() {
 }
 
 func (s *cubeScene) HandleInput(keyEvents []windows.KeyboardInputEvent, mouseEvents []windows.MouseInputEvent) windows.WindowAction {
-	// nothing for now
-	for _, event := range keyEvents {
-		switch event.Key {
-		case glfw.KeyEscape:
-			return windows.WindowActionExit
-		case glfw.KeySpace:
-			if event.Action == glfw.Press {
-				s.showText = !s.showText
-			}
-		}
+	actions := windows.Actions()
+	if actions.Pressed("exit") {
+		return windows.WindowActionExit
+	}
+	if actions.Pressed("toggle_text") {
+		s.showText = !s.showText
 	}
 	return windows.WindowActionNone
 }
@@ -188,14 +201,15 @@ func (s *cubeScene) Init() {
 	s.program = program
 
 	gl.UseProgram(program)
-
-	projection := mgl32.Perspective(mgl32.DegToRad(45.0), float32(windowWidth)/windowHeight, 0.1, 10.0)
-	projectionUniform := gl.GetUniformLocation(program, gl.Str("projection\x00"))
-	gl.UniformMatrix4fv(projectionUniform, 1, false, &projection[0])
-
-	camera := mgl32.LookAtV(mgl32.Vec3{3, 3, 3}, mgl32.Vec3{0, 0, 0}, mgl32.Vec3{0, 1, 0})
-	cameraUniform := gl.GetUniformLocation(program, gl.Str("camera\x00"))
-	gl.UniformMatrix4fv(cameraUniform, 1, false, &camera[0])
+	graphics.BindCameraUniforms(program)
+
+	// look at the cube from (3, 3, 3). The aspect ratio follows the window size.
+	s.camera = graphics.NewCamera3D()
+	s.camera.SetPerspective(mgl32.DegToRad(45.0), 0.1, 10.0)
+	s.orbit = graphics.NewOrbitController(mgl32.Vec3{0, 0, 0}, float32(math.Sqrt(27)))
+	s.orbit.Rotate(3*math.Pi/4, float32(math.Asin(1/math.Sqrt(3))))
+	s.orbit.Apply(s.camera)
+	graphics.SetCamera3D(s.camera)
 
 	s.model = mgl32.Ident4()
 	s.modelUniform = gl.GetUniformLocation(program, gl.Str("model\x00"))
@@ -3,7 +3,6 @@ package main
 import (
 	"fmt"
 	"math"
-	"math/rand"
 
 	"github.com/go-gl/gl/v3.3-core/gl"
 	"github.com/go-gl/glfw/v3.2/glfw"
@@ -75,14 +74,17 @@ func (s *backgroundScene) Init() {
 	for xi := float32(-800); xi <= 800; xi += 100 {
 		for yi := float32(-800); yi <= 800; yi += 100 {
 			s.pointManager.AddObject(&rendergroups.GenericObject2D{
-				Coords: []mgl32.Vec3{mgl32.Vec3{xi, yi, rand.Float32()*2 - 1}},
+				Coords: []mgl32.Vec3{mgl32.Vec3{xi, yi, windows.Rand().Float32()*2 - 1}},
 				Color:  mgl32.Vec4{1, 1, 1, 1},
 			})
 		}
 	}
 
+	// the triangle first, then the lines and points on top of it
+	graphics.AddRenderGroupOrdered(s.renderGroups[1], graphics.RenderOrder{Layer: graphics.LayerOpaque})
+	graphics.AddRenderGroupOrdered(s.renderGroups[0], graphics.RenderOrder{Layer: graphics.LayerTransparent})
+	graphics.AddRenderGroupOrdered(s.renderGroups[2], graphics.RenderOrder{Layer: graphics.LayerTransparent, Priority: 1})
 	for _, g := range s.renderGroups {
-		graphics.AddRenderGroup(g)
 		g.SetDepthTestMode(true, gl.LESS)
 	}
 
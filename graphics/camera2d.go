@@ -0,0 +1,134 @@
+package graphics
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Camera2D is a view into a 2D world that can be moved, zoomed and rotated.
+// World coordinates have y pointing up. A camera at (0, 0) with zoom 1 and no rotation
+// shows the same area as NormalMatrixOrthoOrigo: one world unit is one pixel and origo is in the middle.
+// The size of the view comes from the viewport of the current context, or from the render target while one is bound.
+type Camera2D struct {
+	position mgl32.Vec2
+	zoom     float32
+	rotation float32
+
+	bounded              bool
+	boundsMin, boundsMax mgl32.Vec2
+}
+
+// NewCamera2D creates a camera at origo with zoom 1
+func NewCamera2D() *Camera2D {
+	return &Camera2D{zoom: 1}
+}
+
+// SetPosition moves the center of the view to (x, y), clamped to the bounds
+func (c *Camera2D) SetPosition(x, y float32) {
+	c.position = mgl32.Vec2{x, y}
+	c.position = c.clamp(c.position)
+}
+
+// Move moves the camera by (dx, dy) world units
+func (c *Camera2D) Move(dx, dy float32) {
+	c.SetPosition(c.position[0]+dx, c.position[1]+dy)
+}
+
+// Position returns the center of the view in world coordinates
+func (c *Camera2D) Position() mgl32.Vec2 {
+	return c.clamp(c.position)
+}
+
+// SetZoom sets how many pixels one world unit covers. Zooming in may move the camera
+// back inside its bounds.
+func (c *Camera2D) SetZoom(zoom float32) {
+	if zoom <= 0 {
+		panic("camera zoom must be positive")
+	}
+	c.zoom = zoom
+	c.position = c.clamp(c.position)
+}
+
+// Zoom returns the zoom of the camera
+func (c *Camera2D) Zoom() float32 {
+	return c.zoom
+}
+
+// SetRotation rotates the view counterclockwise by angle radians
+func (c *Camera2D) SetRotation(angle float32) {
+	c.rotation = angle
+}
+
+// Rotation returns the rotation of the view in radians
+func (c *Camera2D) Rotation() float32 {
+	return c.rotation
+}
+
+// SetBounds keeps the view inside the rectangle min -> max. If the view is larger than the
+// bounds, it is centered on them. Rotation is not taken into account.
+func (c *Camera2D) SetBounds(min, max mgl32.Vec2) {
+	c.bounded = true
+	c.boundsMin, c.boundsMax = min, max
+	c.position = c.clamp(c.position)
+}
+
+// ClearBounds lets the camera move freely
+func (c *Camera2D) ClearBounds() {
+	c.bounded = false
+}
+
+// ViewSize returns the size of the view in world units
+func (c *Camera2D) ViewSize() mgl32.Vec2 {
+	width, height := mLoop.renderSize()
+	return mgl32.Vec2{width / c.zoom, height / c.zoom}
+}
+
+// clamp returns the position moved so that the view is inside the bounds
+func (c *Camera2D) clamp(position mgl32.Vec2) mgl32.Vec2 {
+	if !c.bounded {
+		return position
+	}
+	half := c.ViewSize().Mul(0.5)
+	for i := range position {
+		min, max := c.boundsMin[i]+half[i], c.boundsMax[i]-half[i]
+		if min > max {
+			position[i] = (c.boundsMin[i] + c.boundsMax[i]) / 2
+		} else if position[i] < min {
+			position[i] = min
+		} else if position[i] > max {
+			position[i] = max
+		}
+	}
+	return position
+}
+
+// ViewMatrix returns the matrix that transforms world coordinates to view coordinates
+// centered on the camera, in pixels
+func (c *Camera2D) ViewMatrix() mgl32.Mat4 {
+	position := c.Position()
+	return mgl32.Scale3D(c.zoom, c.zoom, 1).
+		Mul4(mgl32.HomogRotate3DZ(-c.rotation)).
+		Mul4(mgl32.Translate3D(-position[0], -position[1], 0))
+}
+
+// Matrix returns the matrix that transforms world coordinates to clip space.
+// It replaces the normal matrix of render groups using the camera.
+func (c *Camera2D) Matrix() mgl32.Mat4 {
+	return getNormalMatrixOrthoOrigo(mLoop.renderSize()).Mul4(c.ViewMatrix())
+}
+
+// ScreenToWorld converts a position in viewport pixels, with (0, 0) in the top left corner,
+// to world coordinates. Cursor positions are in screen coordinates, which differ from
+// pixels by the content scale of the window on high DPI displays.
+func (c *Camera2D) ScreenToWorld(screen mgl32.Vec2) mgl32.Vec2 {
+	width, height := mLoop.renderSize()
+	view := mgl32.Vec2{screen[0] - width/2, height/2 - screen[1]}.Mul(1 / c.zoom)
+	return mgl32.Rotate2D(c.rotation).Mul2x1(view).Add(c.Position())
+}
+
+// WorldToScreen converts world coordinates to a position in viewport pixels, with (0, 0)
+// in the top left corner
+func (c *Camera2D) WorldToScreen(world mgl32.Vec2) mgl32.Vec2 {
+	view := mgl32.Rotate2D(-c.rotation).Mul2x1(world.Sub(c.Position())).Mul(c.zoom)
+	width, height := mLoop.renderSize()
+	return mgl32.Vec2{view[0] + width/2, height/2 - view[1]}
+}
@@ -0,0 +1,211 @@
+package graphics
+
+import (
+	"unsafe"
+
+	gl "github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/krapulacoders/krapulaengine2/graphics/errors"
+)
+
+// ProjectionMode is the kind of projection a 3D camera uses
+type ProjectionMode int
+
+// Projection modes
+const (
+	ProjectionPerspective ProjectionMode = iota
+	ProjectionOrthographic
+)
+
+// CameraUniformBlock is the name of the uniform block the current 3D camera is shared in.
+// Shaders declare it as
+//
+//	layout(std140) uniform Camera {
+//	    mat4 view;
+//	    mat4 projection;
+//	    vec4 cameraPosition;
+//	};
+const CameraUniformBlock = "Camera"
+
+// CameraBindingPoint is the uniform buffer binding point of the camera uniform block
+const CameraBindingPoint = 0
+
+// size of the camera uniform block with the std140 layout: two mat4s and a vec4
+const cameraUniformBlockSize = 2*16*4 + 4*4
+
+// Camera3D is a camera looking at a 3D world with a perspective or an orthographic projection.
+// Unless a fixed aspect ratio is set, the aspect ratio follows the viewport of the current context,
+// so it is updated by SetViewPortSize when the window is resized. While a render target is bound,
+// the aspect ratio of the target is used.
+type Camera3D struct {
+	position, target, up mgl32.Vec3
+
+	mode ProjectionMode
+	// vertical field of view in radians for perspective projection,
+	// height of the view in world units for orthographic projection
+	fovy, height float32
+	near, far    float32
+	aspect       float32
+}
+
+// NewCamera3D creates a camera at (0, 0, 1) looking at origo with a 45 degree perspective projection
+func NewCamera3D() *Camera3D {
+	return &Camera3D{
+		position: mgl32.Vec3{0, 0, 1},
+		up:       mgl32.Vec3{0, 1, 0},
+		mode:     ProjectionPerspective,
+		fovy:     mgl32.DegToRad(45),
+		height:   2,
+		near:     0.1,
+		far:      100,
+	}
+}
+
+// SetPerspective makes the camera use a perspective projection with a vertical field of view of fovy radians
+func (c *Camera3D) SetPerspective(fovy, near, far float32) {
+	c.mode = ProjectionPerspective
+	c.fovy, c.near, c.far = fovy, near, far
+}
+
+// SetOrthographic makes the camera use an orthographic projection showing height world units vertically
+func (c *Camera3D) SetOrthographic(height, near, far float32) {
+	c.mode = ProjectionOrthographic
+	c.height, c.near, c.far = height, near, far
+}
+
+// ProjectionMode returns the kind of projection the camera uses
+func (c *Camera3D) ProjectionMode() ProjectionMode {
+	return c.mode
+}
+
+// SetFixedAspect fixes the aspect ratio (width / height) of the camera.
+// 0 makes it follow the viewport again.
+func (c *Camera3D) SetFixedAspect(aspect float32) {
+	c.aspect = aspect
+}
+
+// Aspect returns the aspect ratio (width / height) of the camera
+func (c *Camera3D) Aspect() float32 {
+	if c.aspect > 0 {
+		return c.aspect
+	}
+	width, height := mLoop.renderSize()
+	if height == 0 {
+		return 1
+	}
+	return width / height
+}
+
+// SetPosition moves the camera. It keeps looking at its target.
+func (c *Camera3D) SetPosition(position mgl32.Vec3) {
+	c.position = position
+}
+
+// Position returns the position of the camera
+func (c *Camera3D) Position() mgl32.Vec3 {
+	return c.position
+}
+
+// LookAt turns the camera towards target
+func (c *Camera3D) LookAt(target mgl32.Vec3) {
+	c.target = target
+}
+
+// Target returns the point the camera looks at
+func (c *Camera3D) Target() mgl32.Vec3 {
+	return c.target
+}
+
+// SetUp sets which direction is up for the camera, (0, 1, 0) by default
+func (c *Camera3D) SetUp(up mgl32.Vec3) {
+	c.up = up
+}
+
+// Forward returns the unit vector the camera looks along
+func (c *Camera3D) Forward() mgl32.Vec3 {
+	return c.target.Sub(c.position).Normalize()
+}
+
+// ViewMatrix returns the matrix that transforms world coordinates to camera coordinates
+func (c *Camera3D) ViewMatrix() mgl32.Mat4 {
+	return mgl32.LookAtV(c.position, c.target, c.up)
+}
+
+// ProjectionMatrix returns the matrix that transforms camera coordinates to clip space
+func (c *Camera3D) ProjectionMatrix() mgl32.Mat4 {
+	aspect := c.Aspect()
+	if c.mode == ProjectionOrthographic {
+		halfHeight := c.height / 2
+		halfWidth := halfHeight * aspect
+		return mgl32.Ortho(-halfWidth, halfWidth, -halfHeight, halfHeight, c.near, c.far)
+	}
+	return mgl32.Perspective(c.fovy, aspect, c.near, c.far)
+}
+
+// Matrix returns the matrix that transforms world coordinates to clip space
+func (c *Camera3D) Matrix() mgl32.Mat4 {
+	return c.ProjectionMatrix().Mul4(c.ViewMatrix())
+}
+
+// SetCamera3D makes the camera the 3D camera of the current context. Its matrices are
+// uploaded to the camera uniform block at the start of every frame and whenever a render target
+// is bound or unbound. nil removes the camera.
+func SetCamera3D(camera *Camera3D) {
+	mLoop.camera3D = camera
+}
+
+// GetCamera3D returns the 3D camera of the current context, or nil if it has none
+func GetCamera3D() *Camera3D {
+	return mLoop.camera3D
+}
+
+// BindCameraUniforms binds the camera uniform block of a shader program to the camera uniform buffer.
+// Programs that don't declare the block are left as they are.
+func BindCameraUniforms(program uint32) {
+	index := gl.GetUniformBlockIndex(program, gl.Str(CameraUniformBlock+"\x00"))
+	if index == gl.INVALID_INDEX {
+		return
+	}
+	gl.UniformBlockBinding(program, index, CameraBindingPoint)
+	errors.AssertGLError(errors.Normal, "glUniformBlockBinding")
+}
+
+// UpdateCameraUniforms uploads the matrices of the 3D camera to the camera uniform buffer.
+// It is done by BeginFrame and when a render target is bound or unbound,
+// call it again if the camera is changed during the frame.
+func UpdateCameraUniforms() {
+	mLoop.updateCameraUniforms()
+}
+
+func (c *Context) updateCameraUniforms() {
+	if c.headless || c.camera3D == nil {
+		return
+	}
+	if c.cameraUBO == 0 {
+		gl.GenBuffers(1, &c.cameraUBO)
+		gl.BindBuffer(gl.UNIFORM_BUFFER, c.cameraUBO)
+		gl.BufferData(gl.UNIFORM_BUFFER, cameraUniformBlockSize, nil, gl.DYNAMIC_DRAW)
+		gl.BindBufferBase(gl.UNIFORM_BUFFER, CameraBindingPoint, c.cameraUBO)
+		errors.AssertGLError(errors.Critical, "camera uniform buffer")
+	}
+
+	var block struct {
+		view, projection mgl32.Mat4
+		position         mgl32.Vec4
+	}
+	block.view = c.camera3D.ViewMatrix()
+	block.projection = c.camera3D.ProjectionMatrix()
+	block.position = c.camera3D.position.Vec4(1)
+
+	gl.BindBuffer(gl.UNIFORM_BUFFER, c.cameraUBO)
+	gl.BufferSubData(gl.UNIFORM_BUFFER, 0, cameraUniformBlockSize, unsafe.Pointer(&block))
+	gl.BindBuffer(gl.UNIFORM_BUFFER, 0)
+	errors.AssertGLError(errors.Normal, "update camera uniforms")
+}
+
+func (c *Context) deleteCameraUniforms() {
+	if c.cameraUBO != 0 {
+		gl.DeleteBuffers(1, &c.cameraUBO)
+		c.cameraUBO = 0
+	}
+}
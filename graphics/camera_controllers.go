@@ -0,0 +1,119 @@
+package graphics
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// CameraController moves a 3D camera. Controllers keep their own state, which is fed by
+// the game from its input, and Apply sets the camera from it, typically once per tick.
+type CameraController interface {
+	Apply(camera *Camera3D)
+}
+
+// how close to straight up or down the pitch of controllers may get
+const maxPitch = math.Pi/2 - 0.01
+
+func clampPitch(pitch float32) float32 {
+	return float32(math.Max(-maxPitch, math.Min(maxPitch, float64(pitch))))
+}
+
+// direction returns the unit vector with the given yaw around the y axis and pitch up from the xz plane.
+// Yaw 0 and pitch 0 point along -z.
+func direction(yaw, pitch float32) mgl32.Vec3 {
+	sinYaw, cosYaw := math.Sincos(float64(yaw))
+	sinPitch, cosPitch := math.Sincos(float64(pitch))
+	return mgl32.Vec3{float32(sinYaw * cosPitch), float32(sinPitch), float32(-cosYaw * cosPitch)}
+}
+
+// OrbitController circles the camera around a target, e.g. for inspecting a model
+type OrbitController struct {
+	Target mgl32.Vec3
+	// Distance from the target, kept between MinDistance and MaxDistance when zooming
+	Distance, MinDistance, MaxDistance float32
+	// angles in radians of the direction from the target to the camera.
+	// Positive pitch puts the camera above the target.
+	Yaw, Pitch float32
+}
+
+// NewOrbitController creates a controller circling target at the given distance
+func NewOrbitController(target mgl32.Vec3, distance float32) *OrbitController {
+	return &OrbitController{
+		Target:      target,
+		Distance:    distance,
+		MinDistance: 0.1,
+		MaxDistance: float32(math.Inf(1)),
+	}
+}
+
+// Rotate moves the camera around the target by the given angles in radians
+func (o *OrbitController) Rotate(yaw, pitch float32) {
+	o.Yaw += yaw
+	o.Pitch = clampPitch(o.Pitch + pitch)
+}
+
+// Zoom multiplies the distance to the target by factor
+func (o *OrbitController) Zoom(factor float32) {
+	o.Distance = float32(math.Max(float64(o.MinDistance), math.Min(float64(o.MaxDistance), float64(o.Distance*factor))))
+}
+
+// Apply places the camera on the orbit, looking at the target
+func (o *OrbitController) Apply(camera *Camera3D) {
+	camera.SetPosition(o.Target.Add(direction(o.Yaw, o.Pitch).Mul(o.Distance)))
+	camera.LookAt(o.Target)
+}
+
+// FPSController walks the camera on the xz plane and turns it with yaw and pitch, like in a first person shooter
+type FPSController struct {
+	Position mgl32.Vec3
+	// angles in radians of the view direction
+	Yaw, Pitch float32
+	// Speed is the movement speed in world units per second
+	Speed float32
+}
+
+// NewFPSController creates a controller at position looking along -z
+func NewFPSController(position mgl32.Vec3, speed float32) *FPSController {
+	return &FPSController{Position: position, Speed: speed}
+}
+
+// Look turns the view by the given angles in radians
+func (f *FPSController) Look(yaw, pitch float32) {
+	f.Yaw += yaw
+	f.Pitch = clampPitch(f.Pitch + pitch)
+}
+
+// Move walks forward and right, scaled by Speed and timedelta. Negative values walk backward and left.
+// Looking up or down doesn't change the walking direction.
+func (f *FPSController) Move(forward, right float32, timedelta float64) {
+	step := f.Speed * float32(timedelta)
+	front := direction(f.Yaw, 0)
+	side := direction(f.Yaw+math.Pi/2, 0)
+	f.Position = f.Position.Add(front.Mul(forward * step)).Add(side.Mul(right * step))
+}
+
+// Apply places the camera at the position, looking in the view direction
+func (f *FPSController) Apply(camera *Camera3D) {
+	camera.SetPosition(f.Position)
+	camera.LookAt(f.Position.Add(direction(f.Yaw, f.Pitch)))
+}
+
+// FlyController moves the camera freely in the direction it looks at
+type FlyController struct {
+	FPSController
+}
+
+// NewFlyController creates a controller at position looking along -z
+func NewFlyController(position mgl32.Vec3, speed float32) *FlyController {
+	return &FlyController{FPSController{Position: position, Speed: speed}}
+}
+
+// Move flies forward along the view direction, right and up, scaled by Speed and timedelta
+func (f *FlyController) Move(forward, right, up float32, timedelta float64) {
+	step := f.Speed * float32(timedelta)
+	front := direction(f.Yaw, f.Pitch)
+	side := direction(f.Yaw+math.Pi/2, 0)
+	f.Position = f.Position.Add(front.Mul(forward * step)).Add(side.Mul(right * step)).
+		Add(mgl32.Vec3{0, up * step, 0})
+}
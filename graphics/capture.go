@@ -0,0 +1,52 @@
+package graphics
+
+import (
+	"image"
+
+	gl "github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/krapulacoders/krapulaengine2/graphics/errors"
+)
+
+// CaptureFrame reads the framebuffer bound for reading into an image. That is the window
+// unless a render target is bound, in which case the image has the size of the target.
+// Capture before swapping buffers, the back buffer is undefined afterwards.
+// Headless contexts return a blank image.
+func CaptureFrame() *image.RGBA {
+	width, height := mLoop.renderSize()
+	return CaptureRegion(0, 0, int(width), int(height))
+}
+
+// Capture reads the color texture of the target into an image, whether the target is bound or not
+func (t *RenderTarget) Capture() *image.RGBA {
+	if t.headless {
+		return image.NewRGBA(image.Rect(0, 0, t.width, t.height))
+	}
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, t.fbo)
+	img := CaptureRegion(0, 0, t.width, t.height)
+	gl.BindFramebuffer(gl.READ_FRAMEBUFFER, mLoop.boundFramebuffer())
+	return img
+}
+
+// CaptureRegion reads a region of the framebuffer bound for reading into an image.
+// x and y are in GL coordinates, from the bottom left corner.
+func CaptureRegion(x, y, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	if mLoop.headless || width <= 0 || height <= 0 {
+		return img
+	}
+	pixels := make([]uint8, width*height*4)
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(int32(x), int32(y), int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(pixels))
+	errors.AssertGLError(errors.Normal, "glReadPixels")
+	flipRows(pixels, img.Pix, width*4)
+	return img
+}
+
+// flipRows copies the rows of src to dst in reverse order, since GL
+// images start from the bottom row and Go images from the top row.
+func flipRows(src, dst []uint8, stride int) {
+	rows := len(src) / stride
+	for row := 0; row < rows; row++ {
+		copy(dst[row*stride:(row+1)*stride], src[(rows-row-1)*stride:(rows-row)*stride])
+	}
+}
@@ -0,0 +1,35 @@
+package graphics
+
+import (
+	"image"
+	"testing"
+)
+
+func TestCaptureRenderTargetSize(t *testing.T) {
+	previous := mLoop
+	mLoop = NewHeadlessContext()
+	defer func() { mLoop = previous }()
+	SetViewPortSize(800, 600)
+
+	target, err := NewRenderTarget(64, 32, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Delete()
+
+	if bounds := CaptureFrame().Bounds(); bounds != image.Rect(0, 0, 800, 600) {
+		t.Errorf("expected the window size, got %v", bounds)
+	}
+	if bounds := target.Capture().Bounds(); bounds != image.Rect(0, 0, 64, 32) {
+		t.Errorf("expected the target size, got %v", bounds)
+	}
+
+	target.Bind()
+	if bounds := CaptureFrame().Bounds(); bounds != image.Rect(0, 0, 64, 32) {
+		t.Errorf("expected the size of the bound target, got %v", bounds)
+	}
+	UnbindRenderTarget()
+	if bounds := CaptureFrame().Bounds(); bounds != image.Rect(0, 0, 800, 600) {
+		t.Errorf("expected the window size after unbinding, got %v", bounds)
+	}
+}
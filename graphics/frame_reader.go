@@ -0,0 +1,107 @@
+package graphics
+
+import (
+	"image"
+
+	gl "github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/krapulacoders/krapulaengine2/graphics/errors"
+)
+
+// FrameReader reads frames back from the GPU through a ring of pixel buffer objects.
+// A frame is only mapped once the ring has come around to it, so reading it
+// doesn't stall until the GPU has finished rendering.
+type FrameReader struct {
+	width, height int
+	pbos          []uint32
+	// index of the oldest queued frame and the number of queued frames
+	first, queued int
+	headless      bool
+}
+
+// NewFrameReader creates a reader for frames of the given size using the given number of buffers
+func NewFrameReader(width, height, buffers int) *FrameReader {
+	if buffers < 1 {
+		buffers = 1
+	}
+	r := &FrameReader{width: width, height: height, headless: mLoop.headless}
+	r.pbos = make([]uint32, buffers)
+	if r.headless {
+		return r
+	}
+	gl.GenBuffers(int32(buffers), &r.pbos[0])
+	for _, pbo := range r.pbos {
+		gl.BindBuffer(gl.PIXEL_PACK_BUFFER, pbo)
+		gl.BufferData(gl.PIXEL_PACK_BUFFER, width*height*4, nil, gl.STREAM_READ)
+	}
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+	errors.AssertGLError(errors.Critical, "FrameReader buffers")
+	return r
+}
+
+// Queue starts reading the framebuffer bound for reading. When all buffers are in use
+// the oldest frame is returned, otherwise ok is false.
+func (r *FrameReader) Queue() (img *image.RGBA, ok bool) {
+	if r.queued == len(r.pbos) {
+		img, ok = r.next()
+	}
+	index := (r.first + r.queued) % len(r.pbos)
+	r.queued++
+	if r.headless {
+		return
+	}
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, r.pbos[index])
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	gl.ReadPixels(0, 0, int32(r.width), int32(r.height), gl.RGBA, gl.UNSIGNED_BYTE, gl.PtrOffset(0))
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+	errors.AssertGLError(errors.Normal, "glReadPixels")
+	return
+}
+
+// Size returns the size of the frames the reader reads
+func (r *FrameReader) Size() (int, int) {
+	return r.width, r.height
+}
+
+// Flush waits for and returns all queued frames, oldest first
+func (r *FrameReader) Flush() []*image.RGBA {
+	images := make([]*image.RGBA, 0, r.queued)
+	for r.queued > 0 {
+		img, _ := r.next()
+		images = append(images, img)
+	}
+	return images
+}
+
+// next maps the oldest queued frame and copies it into an image
+func (r *FrameReader) next() (*image.RGBA, bool) {
+	if r.queued == 0 {
+		return nil, false
+	}
+	pbo := r.pbos[r.first]
+	r.first = (r.first + 1) % len(r.pbos)
+	r.queued--
+
+	img := image.NewRGBA(image.Rect(0, 0, r.width, r.height))
+	if r.headless {
+		return img, true
+	}
+	size := r.width * r.height * 4
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, pbo)
+	if ptr := gl.MapBuffer(gl.PIXEL_PACK_BUFFER, gl.READ_ONLY); ptr != nil {
+		pixels := (*[1 << 30]uint8)(ptr)[:size:size]
+		flipRows(pixels, img.Pix, r.width*4)
+		gl.UnmapBuffer(gl.PIXEL_PACK_BUFFER)
+	}
+	gl.BindBuffer(gl.PIXEL_PACK_BUFFER, 0)
+	errors.AssertGLError(errors.Normal, "FrameReader map buffer")
+	return img, true
+}
+
+// Delete releases the buffers. Queued frames are lost.
+func (r *FrameReader) Delete() {
+	if !r.headless && len(r.pbos) > 0 {
+		gl.DeleteBuffers(int32(len(r.pbos)), &r.pbos[0])
+	}
+	r.pbos = nil
+	r.queued = 0
+}
@@ -49,13 +49,22 @@ func (self *TopContainerType) Render() {
 	for _, child := range self.children {
 		child.Render()
 	}
+
+	gl.Disable(gl.BLEND)
+	gl.Enable(gl.DEPTH_TEST)
 }
 
+// HandleInput passes the input to the children that accept it. A child can return
+// WindowActionConsumeInput to keep the input from reaching the scenes below the GUI.
 func (self *TopContainerType) HandleInput(key_events []windows.KeyboardInputEvent,
 	mouse_events []windows.MouseInputEvent) windows.WindowAction {
-	// nothing for now
-	for _, event := range key_events {
-		_ = event
+	for _, child := range self.children {
+		if !child.AcceptsInput() {
+			continue
+		}
+		if action := child.HandleInput(key_events, mouse_events); action != windows.WindowActionNone {
+			return action
+		}
 	}
 	return windows.WindowActionNone
 }
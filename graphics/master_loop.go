@@ -6,17 +6,33 @@ import (
 	"github.com/krapulacoders/krapulaengine2/graphics/errors"
 )
 
-type masterLoop struct {
-	rendergroups                map[int]*RenderGroup
+// Context holds the render groups and render settings of one GL context, i.e. one window.
+// The package level functions operate on the current context.
+type Context struct {
+	rendergroups map[int]*RenderGroup
+	renderOrders map[int]RenderOrder
+	// ids of the render groups in render order
+	renderOrder                 []int
 	nextFreeIndex               int
 	clearColorChanged           bool
 	clearColor                  [4]float32
 	width, height               float32
 	viewportChanged             bool
 	precalculatedNormalMatrices [2]mgl32.Mat4
+	interpolationAlpha          float32
+	camera3D                    *Camera3D
+	cameraUBO                   uint32
+	postProcess                 *PostProcessStack
+	// the target the frame is rendered to before post-processing, nil when rendering to the window
+	frameTarget *RenderTarget
+	// the render target that is bound, nil when rendering to the window
+	boundTarget *RenderTarget
+	// headless contexts have no GL context and don't render anything
+	headless bool
 }
 
-var mLoop masterLoop
+// the current context. The default one is only used until a window creates its own.
+var mLoop = newContext()
 
 // Normal matrix IDs
 const (
@@ -24,27 +40,69 @@ const (
 	NormalMatrixOrthoScreenCords = iota
 )
 
+func newContext() *Context {
+	return &Context{
+		rendergroups:       make(map[int]*RenderGroup),
+		renderOrders:       make(map[int]RenderOrder),
+		clearColor:         [4]float32{1, 0, 1, 0},
+		interpolationAlpha: 1,
+	}
+}
+
 // InitMasterLoop must be called before starting the graphics system using Start().
+// It creates a context for the current GL context and makes it current.
 func InitMasterLoop() {
-	mLoop = masterLoop{
-		rendergroups: make(map[int]*RenderGroup),
-	}
+	mLoop = NewContext()
+}
+
+// NewContext creates a context for the GL context that is current on this thread.
+func NewContext() *Context {
+	c := newContext()
 
 	// Initialize Glow
 	if err := gl.Init(); err != nil {
 		panic(err)
 	}
-	gl.ClearColor(1, 0, 1, 0)
+	gl.ClearColor(c.clearColor[0], c.clearColor[1], c.clearColor[2], c.clearColor[3])
 	gl.Enable(gl.DEPTH_TEST)
 	gl.Enable(gl.VERTEX_PROGRAM_POINT_SIZE)
 	gl.Enable(gl.LINE_SMOOTH)
 	gl.DepthMask(false)
 	gl.Hint(gl.LINE_SMOOTH_HINT, gl.NICEST)
+	return c
+}
+
+// NewHeadlessContext creates a context that isn't backed by a GL context.
+// Rendering it does nothing, which lets windows run without a display.
+func NewHeadlessContext() *Context {
+	c := newContext()
+	c.headless = true
+	return c
+}
+
+// MakeContextCurrent makes the package level functions operate on c.
+// The GL context of c must be made current at the same time.
+func MakeContextCurrent(c *Context) {
+	mLoop = c
 }
 
-func reCalculateNormalMatrices() {
-	mLoop.precalculatedNormalMatrices[NormalMatrixOrthoOrigo] = getNormalMatrixOrthoOrigo()
-	mLoop.precalculatedNormalMatrices[NormalMatrixOrthoScreenCords] = getNormalMatrixOrthoScreenCords()
+// CurrentContext returns the current context
+func CurrentContext() *Context {
+	return mLoop
+}
+
+func (c *Context) reCalculateNormalMatrices() {
+	c.precalculatedNormalMatrices[NormalMatrixOrthoOrigo] = getNormalMatrixOrthoOrigo(c.width, c.height)
+	c.precalculatedNormalMatrices[NormalMatrixOrthoScreenCords] = getNormalMatrixOrthoScreenCords(c.width, c.height)
+}
+
+// renderSize returns the size of what is being rendered to: the bound render target, or the viewport
+func (c *Context) renderSize() (float32, float32) {
+	if c.boundTarget != nil {
+		width, height := c.boundTarget.Size()
+		return float32(width), float32(height)
+	}
+	return c.width, c.height
 }
 
 // SetClearColor sets the clear color.
@@ -58,59 +116,109 @@ func SetClearColor(r, g, b, a float32) {
 
 // SetViewPortSize sets the size of the rendering area.
 func SetViewPortSize(x, y int) {
-	mLoop.width, mLoop.height = (float32)(x), (float32)(y)
-	mLoop.viewportChanged = true
-	reCalculateNormalMatrices()
+	mLoop.SetViewPortSize(x, y)
+}
+
+// SetViewPortSize sets the size of the rendering area of the context.
+// It may be called when the context isn't current, e.g. from a window callback.
+func (c *Context) SetViewPortSize(x, y int) {
+	c.width, c.height = (float32)(x), (float32)(y)
+	c.viewportChanged = true
+	c.reCalculateNormalMatrices()
 }
 
 // GetViewPortSize returns the size of the rendering area
 func GetViewPortSize() mgl32.Vec2 {
-	return mgl32.Vec2{(float32)(mLoop.width), (float32)(mLoop.height)}
+	return mLoop.GetViewPortSize()
+}
+
+// GetViewPortSize returns the size of the rendering area of the context
+func (c *Context) GetViewPortSize() mgl32.Vec2 {
+	return mgl32.Vec2{c.width, c.height}
 }
 
-// GetNormalMatrix returns a precalculated normalMatrix
+// SetInterpolationAlpha sets how far (0-1) the frame being rendered is between the last
+// simulation tick and the next one.
+func SetInterpolationAlpha(alpha float32) {
+	mLoop.interpolationAlpha = alpha
+}
+
+// GetInterpolationAlpha returns how far (0-1) the frame being rendered is between the last
+// simulation tick and the next one. Always 1 unless the window uses a fixed timestep.
+func GetInterpolationAlpha() float32 {
+	return mLoop.interpolationAlpha
+}
+
+// GetNormalMatrix returns a precalculated normalMatrix. Use a Camera2D for a view that can scroll and zoom.
+// While a render target is bound, the matrix is for the size of the target.
 func GetNormalMatrix(id int) mgl32.Mat4 {
+	if mLoop.boundTarget != nil {
+		width, height := mLoop.renderSize()
+		if id == NormalMatrixOrthoScreenCords {
+			return getNormalMatrixOrthoScreenCords(width, height)
+		}
+		return getNormalMatrixOrthoOrigo(width, height)
+	}
 	return mLoop.precalculatedNormalMatrices[id]
 }
 
 // getNormalMatrixOrthoOrigo returns a normal matrix centered around origo, with the size of the framebuffer
-func getNormalMatrixOrthoOrigo() mgl32.Mat4 {
-	return mgl32.Ortho(-mLoop.width/2, mLoop.width/2, -mLoop.height/2, mLoop.height/2, -1, 1)
+func getNormalMatrixOrthoOrigo(width, height float32) mgl32.Mat4 {
+	return mgl32.Ortho(-width/2, width/2, -height/2, height/2, -1, 1)
 }
 
 // GetNormalMatrixOrthoScreenCords returns a normal matrix covering (0, 0) -> (width, height)
-func getNormalMatrixOrthoScreenCords() mgl32.Mat4 {
-	return mgl32.Ortho(0, mLoop.width, -mLoop.height, 0, -1, 1)
+func getNormalMatrixOrthoScreenCords(width, height float32) mgl32.Mat4 {
+	return mgl32.Ortho(0, width, -height, 0, -1, 1)
 }
 
-// AddRenderGroup assigns an id to the manager and returns it.
-// This is NOT threadsafe and should not be ran while the graphics loop is running for now
-func AddRenderGroup(g *RenderGroup) int {
-	mLoop.rendergroups[mLoop.nextFreeIndex] = g
-	mLoop.nextFreeIndex++
-	return mLoop.nextFreeIndex
+// DeinitMasterLoop deinits all rendergroups of the current context
+func DeinitMasterLoop() {
+	mLoop.Deinit()
 }
 
-// RemoveRenderGroup removes the specified id.
-// This is NOT threadsafe and should not be ran while the graphics loop is running for now
-func RemoveRenderGroup(id int) {
-	delete(mLoop.rendergroups, id)
+// Deinit deinits all rendergroups of the context. Its GL context must be current.
+func (c *Context) Deinit() {
+	if c.headless {
+		return
+	}
+	for _, id := range c.renderOrder {
+		c.rendergroups[id].Deinit()
+	}
+	c.deleteCameraUniforms()
+	if c.postProcess != nil {
+		c.postProcess.Deinit()
+	}
 }
 
-// GetRenderGroup returns the specified manager
-func GetRenderGroup(id int) *RenderGroup {
-	return mLoop.rendergroups[id]
+// IsHeadless returns true if the current context has no GL context, so nothing should be rendered
+func IsHeadless() bool {
+	return mLoop.headless
 }
 
-// DeinitMasterLoop deinits all rendergroups
-func DeinitMasterLoop() {
-	for _, g := range mLoop.rendergroups {
-		g.Deinit()
+// ReleaseSharedResources deletes the registered textures and the cached shaders. They are
+// shared by all contexts, so this is done when the last window closes, with its context current.
+func ReleaseSharedResources() {
+	if mLoop.headless {
+		return
 	}
+	clearTextureCache()
+	clearShaderCache()
 }
 
-// Render the registered render groups
+// Render clears the frame, renders the registered render groups and applies post-processing
 func Render() {
+	BeginFrame()
+	RenderGroups()
+	ApplyPostProcessing()
+}
+
+// BeginFrame applies changed settings and clears the frame. With a post-processing stack,
+// rendering goes to an offscreen target until ApplyPostProcessing is called.
+func BeginFrame() {
+	if mLoop.headless {
+		return
+	}
 	if mLoop.clearColorChanged {
 		gl.ClearColor(mLoop.clearColor[0], mLoop.clearColor[1], mLoop.clearColor[2], mLoop.clearColor[3])
 		mLoop.clearColorChanged = false
@@ -119,7 +227,14 @@ func Render() {
 		gl.Viewport(0, 0, int32(mLoop.width), int32(mLoop.height))
 		mLoop.viewportChanged = false
 	}
-	// clear screen
+	mLoop.updateCameraUniforms()
+	// with post-processing the frame is rendered offscreen until ApplyPostProcessing
+	if stack := mLoop.postProcess; stack != nil && len(stack.effects) > 0 && stack.begin() {
+		mLoop.frameTarget = stack.scene
+		return
+	}
+	// clear screen. glClear respects the depth mask, which groups without depth testing leave off
+	gl.DepthMask(true)
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
 	errors.AssertGLError(errors.Critical, "glClear")
 
@@ -127,8 +242,30 @@ func Render() {
 	//gl.GetFramebufferAttachmentParameteriv(gl.DRAW_FRAMEBUFFER, gl.DEPTH, gl.FRAMEBUFFER_ATTACHMENT_DEPTH_SIZE, &depthBits)
 	//fmt.Printf("depth bits: %v\n", depthBits)
 	//errors.AssertGLError(errors.Debug, "glGetFramebufferAttachmentParameteriv")
+}
 
-	for _, g := range mLoop.rendergroups {
-		g.Render()
+// RenderGroups renders the registered render groups in render order. Groups with a render target
+// are rendered first, each target is cleared and gets its groups, and then the rest go to the window.
+func RenderGroups() {
+	if mLoop.headless {
+		return
+	}
+	targets := mLoop.renderTargets()
+	for _, target := range targets {
+		target.Bind()
+		target.Clear()
+		for _, id := range mLoop.renderOrder {
+			if g := mLoop.rendergroups[id]; g.target == target {
+				g.Render()
+			}
+		}
+	}
+	if len(targets) > 0 {
+		UnbindRenderTarget()
+	}
+	for _, id := range mLoop.renderOrder {
+		if g := mLoop.rendergroups[id]; g.target == nil {
+			g.Render()
+		}
 	}
 }
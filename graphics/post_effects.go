@@ -0,0 +1,200 @@
+package graphics
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Fragment shaders of the built in effects
+const (
+	blurShader           = "graphics/shaders/post/blur.frag"
+	bloomThresholdShader = "graphics/shaders/post/bloom_threshold.frag"
+	bloomShader          = "graphics/shaders/post/bloom.frag"
+	vignetteShader       = "graphics/shaders/post/vignette.frag"
+	colorGradingShader   = "graphics/shaders/post/color_grading.frag"
+	crtShader            = "graphics/shaders/post/crt.frag"
+)
+
+// ShaderEffect is an effect running a single pass. Its parameters are the uniforms of the pass.
+type ShaderEffect struct {
+	*PostPass
+	// textures bound after the input texture
+	textures []uint32
+}
+
+// NewShaderEffect creates an effect running fragmentShader. The input texture goes to the first sampler
+// and the textures set with SetTexture to the following ones. See NewPostPass.
+func NewShaderEffect(fragmentShader string, samplers ...string) *ShaderEffect {
+	return &ShaderEffect{PostPass: NewPostPass(fragmentShader, samplers...)}
+}
+
+// SetTexture sets the texture of sampler i+1
+func (e *ShaderEffect) SetTexture(i int, texture uint32) {
+	for len(e.textures) <= i {
+		e.textures = append(e.textures, 0)
+	}
+	e.textures[i] = texture
+}
+
+// Apply runs the pass
+func (e *ShaderEffect) Apply(input uint32, output *RenderTarget) {
+	e.Run(output, append([]uint32{input}, e.textures...)...)
+}
+
+// NewVignetteEffect creates an effect darkening the edges of the screen. The uniforms are
+// intensity (0-1), radius, where the darkening ends, and softness, how long it fades.
+func NewVignetteEffect(intensity, radius float32) *ShaderEffect {
+	e := NewShaderEffect(vignetteShader)
+	e.SetUniform("intensity", intensity)
+	e.SetUniform("radius", radius)
+	e.SetUniform("softness", float32(0.45))
+	return e
+}
+
+// NewColorGradingEffect creates an effect mapping the colors through a lookup table texture of lutSize
+// slices of lutSize x lutSize pixels next to each other. Red grows to the right within a slice, green
+// downwards and blue from slice to slice. The uniforms are lutSize and intensity (0-1).
+func NewColorGradingEffect(lut uint32, lutSize int) *ShaderEffect {
+	e := NewShaderEffect(colorGradingShader, "image", "lut")
+	e.SetTexture(0, lut)
+	e.SetUniform("lutSize", float32(lutSize))
+	e.SetUniform("intensity", float32(1))
+	return e
+}
+
+// NewCRTEffect creates an effect that looks like an old CRT screen. The uniforms are curvature,
+// scanlines (0-1), how dark every other line is, and aberration, how far apart the colors are.
+func NewCRTEffect() *ShaderEffect {
+	e := NewShaderEffect(crtShader)
+	e.SetUniform("curvature", float32(0.25))
+	e.SetUniform("scanlines", float32(0.2))
+	e.SetUniform("aberration", float32(0.002))
+	return e
+}
+
+// BlurEffect is a gaussian blur, run as a horizontal and a vertical pass
+type BlurEffect struct {
+	horizontal, vertical *PostPass
+	buffer               *RenderTarget
+}
+
+// NewGaussianBlurEffect creates a blur with samples radius pixels apart
+func NewGaussianBlurEffect(radius float32) *BlurEffect {
+	e := &BlurEffect{
+		horizontal: NewPostPass(blurShader),
+		vertical:   NewPostPass(blurShader),
+	}
+	e.horizontal.SetUniform("direction", mgl32.Vec2{1, 0})
+	e.vertical.SetUniform("direction", mgl32.Vec2{0, 1})
+	e.SetRadius(radius)
+	return e
+}
+
+// SetRadius sets the distance between the samples in pixels. Larger values blur more.
+func (e *BlurEffect) SetRadius(radius float32) {
+	e.horizontal.SetUniform("radius", radius)
+	e.vertical.SetUniform("radius", radius)
+}
+
+// Apply blurs input horizontally to a buffer of the size of output, and then vertically to output
+func (e *BlurEffect) Apply(input uint32, output *RenderTarget) {
+	if mLoop.headless {
+		return
+	}
+	width, height := int(mLoop.width), int(mLoop.height)
+	if output != nil {
+		output.fitViewport()
+		width, height = output.Size()
+	}
+	if e.buffer == nil {
+		buffer, err := NewRenderTarget(width, height, false)
+		if err != nil {
+			panic(err)
+		}
+		e.buffer = buffer
+	} else if w, h := e.buffer.Size(); w != width || h != height {
+		if err := e.buffer.Resize(width, height); err != nil {
+			panic(err)
+		}
+	}
+	e.horizontal.Run(e.buffer, input)
+	e.vertical.Run(output, e.buffer.Texture())
+}
+
+// Deinit deletes the passes and the buffer
+func (e *BlurEffect) Deinit() {
+	e.horizontal.Deinit()
+	e.vertical.Deinit()
+	if e.buffer != nil {
+		e.buffer.Delete()
+		e.buffer = nil
+	}
+}
+
+// BloomEffect makes bright areas glow. The bright parts are picked at half resolution,
+// blurred and added on top of the image.
+type BloomEffect struct {
+	threshold, combine *PostPass
+	blur               *BlurEffect
+	bright, blurred    *RenderTarget
+}
+
+// NewBloomEffect creates a bloom where pixels brighter than threshold (0-1) glow with the given intensity
+func NewBloomEffect(threshold, intensity float32) *BloomEffect {
+	e := &BloomEffect{
+		threshold: NewPostPass(bloomThresholdShader),
+		combine:   NewPostPass(bloomShader, "image", "bloom"),
+		blur:      NewGaussianBlurEffect(1.5),
+	}
+	e.SetThreshold(threshold)
+	e.SetIntensity(intensity)
+	return e
+}
+
+// SetThreshold sets the brightness (0-1) above which pixels glow
+func (e *BloomEffect) SetThreshold(threshold float32) {
+	e.threshold.SetUniform("threshold", threshold)
+}
+
+// SetIntensity sets how strongly the glow is added to the image
+func (e *BloomEffect) SetIntensity(intensity float32) {
+	e.combine.SetUniform("intensity", intensity)
+}
+
+// Blur returns the blur used for the glow, e.g. for changing its radius
+func (e *BloomEffect) Blur() *BlurEffect {
+	return e.blur
+}
+
+// Apply picks the bright parts, blurs them and adds them to the image
+func (e *BloomEffect) Apply(input uint32, output *RenderTarget) {
+	if mLoop.headless {
+		return
+	}
+	if e.bright == nil {
+		bright, err := NewViewportRenderTarget(0.5, false)
+		if err != nil {
+			panic(err)
+		}
+		blurred, err := NewViewportRenderTarget(0.5, false)
+		if err != nil {
+			bright.Delete()
+			panic(err)
+		}
+		e.bright, e.blurred = bright, blurred
+	}
+	e.threshold.Run(e.bright, input)
+	e.blur.Apply(e.bright.Texture(), e.blurred)
+	e.combine.Run(output, input, e.blurred.Texture())
+}
+
+// Deinit deletes the passes and the buffers
+func (e *BloomEffect) Deinit() {
+	e.threshold.Deinit()
+	e.combine.Deinit()
+	e.blur.Deinit()
+	if e.bright != nil {
+		e.bright.Delete()
+		e.blurred.Delete()
+		e.bright, e.blurred = nil, nil
+	}
+}
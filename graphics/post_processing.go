@@ -0,0 +1,271 @@
+package graphics
+
+import (
+	"fmt"
+
+	gl "github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+	"github.com/krapulacoders/krapulaengine2/graphics/errors"
+)
+
+// PostVertexShader is the vertex shader of all post-processing passes. It draws a triangle covering
+// the screen and passes the texture coordinates to the fragment shader as fragTexCoord.
+const PostVertexShader = "graphics/shaders/post/fullscreen.vert"
+
+// PostEffect is a fullscreen effect in a post-processing stack
+type PostEffect interface {
+	// Apply renders the effect of the input texture to output, or to the window if output is nil
+	Apply(input uint32, output *RenderTarget)
+	// Deinit deletes the GL objects of the effect
+	Deinit()
+}
+
+// PostProcessStack renders the frame into an offscreen target and then runs its effects
+// on it in order, the last one rendering to the window.
+type PostProcessStack struct {
+	effects []PostEffect
+	scene   *RenderTarget
+	// the effects render to these in turns
+	buffers [2]*RenderTarget
+}
+
+// NewPostProcessStack creates an empty stack. Make it current with SetPostProcessStack.
+func NewPostProcessStack() *PostProcessStack {
+	return new(PostProcessStack)
+}
+
+// Add adds an effect after the existing ones
+func (s *PostProcessStack) Add(effect PostEffect) {
+	s.effects = append(s.effects, effect)
+}
+
+// Remove removes an effect from the stack. It isn't deinited.
+func (s *PostProcessStack) Remove(effect PostEffect) {
+	for i, other := range s.effects {
+		if other == effect {
+			s.effects = append(s.effects[:i], s.effects[i+1:]...)
+			return
+		}
+	}
+}
+
+// Effects returns the effects in the order they are run
+func (s *PostProcessStack) Effects() []PostEffect {
+	return s.effects
+}
+
+// Deinit deinits the effects and deletes the offscreen targets
+func (s *PostProcessStack) Deinit() {
+	for _, effect := range s.effects {
+		effect.Deinit()
+	}
+	for _, target := range append(s.buffers[:], s.scene) {
+		if target != nil {
+			target.Delete()
+		}
+	}
+	s.scene = nil
+	s.buffers = [2]*RenderTarget{}
+}
+
+// begin binds and clears the target the frame is rendered to
+func (s *PostProcessStack) begin() bool {
+	if s.scene == nil {
+		scene, err := NewViewportRenderTarget(1, true)
+		if err != nil {
+			errors.LogError(errors.Normal, "post-processing disabled: "+err.Error())
+			return false
+		}
+		s.scene = scene
+	}
+	s.scene.clearColor = mLoop.clearColor
+	s.scene.Bind()
+	s.scene.Clear()
+	return true
+}
+
+// apply runs the effects on the rendered frame
+func (s *PostProcessStack) apply() {
+	input := s.scene.Texture()
+	for i, effect := range s.effects {
+		var output *RenderTarget
+		if i < len(s.effects)-1 {
+			output = s.buffer(i % 2)
+			if output == nil {
+				return
+			}
+		}
+		effect.Apply(input, output)
+		if output != nil {
+			input = output.Texture()
+		}
+	}
+}
+
+func (s *PostProcessStack) buffer(i int) *RenderTarget {
+	if s.buffers[i] == nil {
+		buffer, err := NewViewportRenderTarget(1, false)
+		if err != nil {
+			errors.LogError(errors.Normal, "post-processing failed: "+err.Error())
+			return nil
+		}
+		s.buffers[i] = buffer
+	}
+	return s.buffers[i]
+}
+
+// SetPostProcessStack makes the frames of the current context go through the stack.
+// nil renders straight to the window again.
+func SetPostProcessStack(stack *PostProcessStack) {
+	mLoop.postProcess = stack
+}
+
+// GetPostProcessStack returns the post-processing stack of the current context, or nil if it has none
+func GetPostProcessStack() *PostProcessStack {
+	return mLoop.postProcess
+}
+
+// ApplyPostProcessing runs the post-processing stack on what has been rendered since BeginFrame and
+// puts the result in the window. Whatever is rendered after it, like a GUI, is not post-processed.
+func ApplyPostProcessing() {
+	if mLoop.frameTarget == nil {
+		return
+	}
+	mLoop.frameTarget = nil
+	UnbindRenderTarget()
+	mLoop.postProcess.apply()
+}
+
+// PostPass is one fullscreen shader pass. The fragment shader gets the textures it is run with in
+// the samplers named when the pass was created, the texture coordinates in fragTexCoord and the size
+// of the output in pixels in the resolution uniform. Other uniforms are set with SetUniform.
+type PostPass struct {
+	group     *RenderGroup
+	samplers  []string
+	uniforms  map[string]interface{}
+	locations map[string]int32
+	vao       uint32
+	// size of the output of the current run
+	width, height int
+}
+
+// NewPostPass creates a pass running fragmentShader. The textures are bound to the
+// samplers in the given order. With no samplers the only texture goes to "image".
+func NewPostPass(fragmentShader string, samplers ...string) *PostPass {
+	if len(samplers) == 0 {
+		samplers = []string{"image"}
+	}
+	p := &PostPass{
+		samplers:  samplers,
+		uniforms:  make(map[string]interface{}),
+		locations: make(map[string]int32),
+	}
+	p.group = NewRenderGroup(fragmentShader, postPassGroup{p})
+	p.group.SetShaderFile(PostVertexShader)
+	p.group.SetShaderFile(fragmentShader)
+	return p
+}
+
+// SetUniform sets a uniform of the shader. The value can be a float32, an int32, or an mgl32.Vec2, Vec3 or Vec4.
+func (p *PostPass) SetUniform(name string, value interface{}) {
+	switch value.(type) {
+	case float32, int32, mgl32.Vec2, mgl32.Vec3, mgl32.Vec4:
+		p.uniforms[name] = value
+	default:
+		panic(fmt.Sprintf("unsupported uniform type %T for %v", value, name))
+	}
+}
+
+// Uniform returns the value of a uniform set with SetUniform, or nil
+func (p *PostPass) Uniform(name string) interface{} {
+	return p.uniforms[name]
+}
+
+// Run renders the pass with the given textures to output, or to the window if output is nil
+func (p *PostPass) Run(output *RenderTarget, textures ...uint32) {
+	if mLoop.headless {
+		return
+	}
+	if output != nil {
+		output.Bind()
+		p.width, p.height = output.Size()
+	} else {
+		UnbindRenderTarget()
+		p.width, p.height = int(mLoop.width), int(mLoop.height)
+	}
+	for i, texture := range textures {
+		gl.ActiveTexture(gl.TEXTURE0 + uint32(i))
+		gl.BindTexture(gl.TEXTURE_2D, texture)
+	}
+	gl.ActiveTexture(gl.TEXTURE0)
+	p.group.Render()
+}
+
+// Deinit deletes the shader program and the vertex array of the pass
+func (p *PostPass) Deinit() {
+	if !mLoop.headless {
+		p.group.Deinit()
+	}
+}
+
+// postPassGroup is the render group implementation of a pass
+type postPassGroup struct {
+	*PostPass
+}
+
+// InitShader is run once per program
+func (g postPassGroup) InitShader() {
+	program := g.group.GetShaderProgram()
+	gl.BindFragDataLocation(program, 0, gl.Str("outputColor\x00"))
+	g.locations = make(map[string]int32)
+	gl.UseProgram(program)
+	for i, sampler := range g.samplers {
+		gl.Uniform1i(g.location(sampler), int32(i))
+	}
+	if g.vao == 0 {
+		gl.GenVertexArrays(1, &g.vao)
+	}
+	errors.AssertGLError(errors.Normal, "post pass InitShader")
+}
+
+// location returns the location of a uniform, -1 if the shader doesn't use it
+func (p *PostPass) location(name string) int32 {
+	location, ok := p.locations[name]
+	if !ok {
+		location = gl.GetUniformLocation(p.group.GetShaderProgram(), gl.Str(name+"\x00"))
+		p.locations[name] = location
+	}
+	return location
+}
+
+// Render sets the uniforms and draws the fullscreen triangle
+func (g postPassGroup) Render() {
+	gl.Uniform2f(g.location("resolution"), float32(g.width), float32(g.height))
+	for name, value := range g.uniforms {
+		location := g.location(name)
+		switch v := value.(type) {
+		case float32:
+			gl.Uniform1f(location, v)
+		case int32:
+			gl.Uniform1i(location, v)
+		case mgl32.Vec2:
+			gl.Uniform2f(location, v[0], v[1])
+		case mgl32.Vec3:
+			gl.Uniform3f(location, v[0], v[1], v[2])
+		case mgl32.Vec4:
+			gl.Uniform4f(location, v[0], v[1], v[2], v[3])
+		}
+	}
+	gl.BindVertexArray(g.vao)
+	gl.DrawArrays(gl.TRIANGLES, 0, 3)
+	gl.BindVertexArray(0)
+	errors.AssertGLError(errors.Normal, "post pass draw")
+}
+
+// Deinit deletes the vertex array
+func (g postPassGroup) Deinit() {
+	if g.vao != 0 {
+		gl.DeleteVertexArrays(1, &g.vao)
+		g.vao = 0
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	gl "github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
 	"github.com/krapulacoders/krapulaengine2/graphics/errors"
 )
 
@@ -20,6 +21,8 @@ type RenderGroup struct {
 	blendEnabled         bool
 	blendFunc1           uint32
 	blendFunc2           uint32
+	camera               *Camera2D
+	target               *RenderTarget
 
 	impl RenderGroupImplementation
 }
@@ -87,6 +90,7 @@ func (g *RenderGroup) activateShaderProgram() {
 		}
 
 		// then do first-time binding
+		BindCameraUniforms(g.shaderPgm)
 		g.impl.InitShader()
 		g.shaderPgmNeedsRelink = false
 	}
@@ -106,7 +110,7 @@ func (g *RenderGroup) Deinit() {
 	if g.shaderPgm != 0 {
 		gl.UseProgram(0)
 		gl.DeleteProgram(g.shaderPgm)
-
+		g.shaderPgm = 0
 	}
 	g.shaderPgmNeedsRelink = true
 	g.impl.Deinit()
@@ -155,6 +159,36 @@ func (g *RenderGroup) SetBlendingMode(enabled bool, blendFunc1, blendFunc2 uint3
 	g.blendFunc2 = blendFunc2
 }
 
+// SetCamera2D makes the group render through a camera. nil renders without a camera,
+// using NormalMatrixOrthoOrigo.
+func (g *RenderGroup) SetCamera2D(camera *Camera2D) {
+	g.camera = camera
+}
+
+// GetCamera2D returns the camera of the group, or nil if it has none
+func (g *RenderGroup) GetCamera2D() *Camera2D {
+	return g.camera
+}
+
+// GetProjectionMatrix returns the matrix from world coordinates to clip space:
+// the matrix of the camera, or NormalMatrixOrthoOrigo if the group has no camera
+func (g *RenderGroup) GetProjectionMatrix() mgl32.Mat4 {
+	if g.camera != nil {
+		return g.camera.Matrix()
+	}
+	return GetNormalMatrix(NormalMatrixOrthoOrigo)
+}
+
+// SetRenderTarget makes the group render to a render target. nil renders to the window.
+func (g *RenderGroup) SetRenderTarget(target *RenderTarget) {
+	g.target = target
+}
+
+// GetRenderTarget returns the render target of the group, or nil if it renders to the window
+func (g *RenderGroup) GetRenderTarget() *RenderTarget {
+	return g.target
+}
+
 // NewRenderGroup creates a RenderGroup.
 func NewRenderGroup(id string, impl RenderGroupImplementation) *RenderGroup {
 	g := new(RenderGroup)
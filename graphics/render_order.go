@@ -0,0 +1,96 @@
+package graphics
+
+import (
+	"sort"
+)
+
+// RenderLayer is a coarse render order. Groups in lower layers are rendered first.
+type RenderLayer int
+
+// Render layers. They are spaced apart so custom layers can be put between them.
+const (
+	LayerBackground  RenderLayer = 0
+	LayerOpaque      RenderLayer = 100
+	LayerTransparent RenderLayer = 200
+	LayerOverlay     RenderLayer = 300
+)
+
+// RenderOrder decides when a render group is rendered. Groups are sorted by layer, then by priority,
+// lower first. Groups with the same layer and priority are rendered in the order they were added.
+type RenderOrder struct {
+	Layer    RenderLayer
+	Priority int
+}
+
+// before returns true if o is rendered before other
+func (o RenderOrder) before(other RenderOrder) bool {
+	if o.Layer != other.Layer {
+		return o.Layer < other.Layer
+	}
+	return o.Priority < other.Priority
+}
+
+// AddRenderGroup adds a render group to the opaque layer with priority 0 and returns its id.
+// This is NOT threadsafe and should not be ran while the graphics loop is running for now
+func AddRenderGroup(g *RenderGroup) int {
+	return AddRenderGroupOrdered(g, RenderOrder{Layer: LayerOpaque})
+}
+
+// AddRenderGroupOrdered adds a render group with the given render order and returns its id.
+// This is NOT threadsafe and should not be ran while the graphics loop is running for now
+func AddRenderGroupOrdered(g *RenderGroup, order RenderOrder) int {
+	id := mLoop.nextFreeIndex
+	mLoop.nextFreeIndex++
+	mLoop.rendergroups[id] = g
+	mLoop.renderOrders[id] = order
+	mLoop.renderOrder = append(mLoop.renderOrder, id)
+	mLoop.sortRenderGroups()
+	return id
+}
+
+// SetRenderGroupOrder changes the render order of a render group
+func SetRenderGroupOrder(id int, order RenderOrder) {
+	if _, exists := mLoop.rendergroups[id]; !exists {
+		panic("no render group with the given id")
+	}
+	mLoop.renderOrders[id] = order
+	mLoop.sortRenderGroups()
+}
+
+// GetRenderGroupOrder returns the render order of a render group
+func GetRenderGroupOrder(id int) RenderOrder {
+	return mLoop.renderOrders[id]
+}
+
+// RemoveRenderGroup removes the specified id.
+// This is NOT threadsafe and should not be ran while the graphics loop is running for now
+func RemoveRenderGroup(id int) {
+	if _, exists := mLoop.rendergroups[id]; !exists {
+		return
+	}
+	delete(mLoop.rendergroups, id)
+	delete(mLoop.renderOrders, id)
+	for i, other := range mLoop.renderOrder {
+		if other == id {
+			mLoop.renderOrder = append(mLoop.renderOrder[:i], mLoop.renderOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetRenderGroup returns the specified manager
+func GetRenderGroup(id int) *RenderGroup {
+	return mLoop.rendergroups[id]
+}
+
+// sortRenderGroups sorts the render order. Ids grow as groups are added, so sorting
+// by id keeps the groups with the same order in the order they were added.
+func (c *Context) sortRenderGroups() {
+	sort.Slice(c.renderOrder, func(i, j int) bool {
+		a, b := c.renderOrder[i], c.renderOrder[j]
+		if c.renderOrders[a] != c.renderOrders[b] {
+			return c.renderOrders[a].before(c.renderOrders[b])
+		}
+		return a < b
+	})
+}
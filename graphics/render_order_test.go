@@ -0,0 +1,53 @@
+package graphics
+
+import (
+	"reflect"
+	"testing"
+)
+
+// nopGroup is a render group implementation that does nothing
+type nopGroup struct{}
+
+func (nopGroup) Deinit()     {}
+func (nopGroup) InitShader() {}
+func (nopGroup) Render()     {}
+
+func TestRenderOrder(t *testing.T) {
+	previous := mLoop
+	mLoop = NewHeadlessContext()
+	defer func() { mLoop = previous }()
+
+	add := func(order RenderOrder) int {
+		return AddRenderGroupOrdered(NewRenderGroup("test", nopGroup{}), order)
+	}
+	overlay := add(RenderOrder{Layer: LayerOverlay})
+	first := AddRenderGroup(NewRenderGroup("test", nopGroup{}))
+	background := add(RenderOrder{Layer: LayerBackground, Priority: 5})
+	second := AddRenderGroup(NewRenderGroup("test", nopGroup{}))
+	early := add(RenderOrder{Layer: LayerOpaque, Priority: -1})
+	third := AddRenderGroup(NewRenderGroup("test", nopGroup{}))
+
+	expected := []int{background, early, first, second, third, overlay}
+	if !reflect.DeepEqual(mLoop.renderOrder, expected) {
+		t.Errorf("expected layers, then priorities, then the order added: %v, got %v", expected, mLoop.renderOrder)
+	}
+
+	RemoveRenderGroup(second)
+	if GetRenderGroup(second) != nil {
+		t.Errorf("the removed group should be gone")
+	}
+	expected = []int{background, early, first, third, overlay}
+	if !reflect.DeepEqual(mLoop.renderOrder, expected) {
+		t.Errorf("removing shouldn't change the order of the others: %v, got %v", expected, mLoop.renderOrder)
+	}
+
+	// moving a group keeps the ties in the order they were added
+	SetRenderGroupOrder(first, RenderOrder{Layer: LayerOverlay})
+	expected = []int{background, early, third, overlay, first}
+	if !reflect.DeepEqual(mLoop.renderOrder, expected) {
+		t.Errorf("expected %v after moving a group, got %v", expected, mLoop.renderOrder)
+	}
+	if GetRenderGroupOrder(first) != (RenderOrder{Layer: LayerOverlay}) {
+		t.Errorf("unexpected order %v", GetRenderGroupOrder(first))
+	}
+}
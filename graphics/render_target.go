@@ -0,0 +1,233 @@
+package graphics
+
+import (
+	"fmt"
+
+	gl "github.com/go-gl/gl/v3.3-core/gl"
+	"github.com/krapulacoders/krapulaengine2/graphics/errors"
+)
+
+// RenderTarget is an offscreen framebuffer that render groups can render to instead of the window,
+// e.g. for minimaps, mirrors or composing the frame. What is rendered ends up in a color texture
+// that other groups can use. A target belongs to the context it was created in.
+type RenderTarget struct {
+	fbo, colorTexture, depthBuffer uint32
+	width, height                  int
+	depth                          bool
+	// a target with a scale follows the viewport size, scaled
+	scale      float32
+	clearColor [4]float32
+	// the id of the color texture in the texture cache, if registered
+	textureID string
+	headless  bool
+}
+
+// NewRenderTarget creates a render target of a fixed size, with a depth buffer if depth is set
+func NewRenderTarget(width, height int, depth bool) (*RenderTarget, error) {
+	t := &RenderTarget{depth: depth, headless: mLoop.headless}
+	if err := t.Resize(width, height); err != nil {
+		t.Delete()
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewViewportRenderTarget creates a render target that is scale times the size of the viewport.
+// It is resized when the viewport size changes.
+func NewViewportRenderTarget(scale float32, depth bool) (*RenderTarget, error) {
+	if scale <= 0 {
+		panic("render target scale must be positive")
+	}
+	t := &RenderTarget{depth: depth, scale: scale, headless: mLoop.headless}
+	width, height := t.viewportSize()
+	if err := t.Resize(width, height); err != nil {
+		t.Delete()
+		return nil, err
+	}
+	return t, nil
+}
+
+// viewportSize returns the size a viewport sized target should have
+func (t *RenderTarget) viewportSize() (int, int) {
+	width, height := int(mLoop.width*t.scale), int(mLoop.height*t.scale)
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+	return width, height
+}
+
+// fitViewport resizes a viewport sized target if the viewport has changed
+func (t *RenderTarget) fitViewport() {
+	if t.scale <= 0 {
+		return
+	}
+	if width, height := t.viewportSize(); width != t.width || height != t.height {
+		if err := t.Resize(width, height); err != nil {
+			errors.LogError(errors.Normal, err.Error())
+		}
+	}
+}
+
+// Resize changes the size of the attachments. The color texture keeps its id, so it can
+// stay registered. The contents are undefined until the target is rendered to again.
+func (t *RenderTarget) Resize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("invalid render target size %vx%v", width, height)
+	}
+	t.width, t.height = width, height
+	if t.headless {
+		return nil
+	}
+
+	if t.fbo == 0 {
+		gl.GenFramebuffers(1, &t.fbo)
+		gl.GenTextures(1, &t.colorTexture)
+		if t.depth {
+			gl.GenRenderbuffers(1, &t.depthBuffer)
+		}
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+
+	gl.BindTexture(gl.TEXTURE_2D, t.colorTexture)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, int32(width), int32(height), 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+	gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, t.colorTexture, 0)
+	errors.AssertGLError(errors.Normal, "render target color attachment")
+
+	if t.depth {
+		gl.BindRenderbuffer(gl.RENDERBUFFER, t.depthBuffer)
+		gl.RenderbufferStorage(gl.RENDERBUFFER, gl.DEPTH24_STENCIL8, int32(width), int32(height))
+		gl.FramebufferRenderbuffer(gl.FRAMEBUFFER, gl.DEPTH_STENCIL_ATTACHMENT, gl.RENDERBUFFER, t.depthBuffer)
+		gl.BindRenderbuffer(gl.RENDERBUFFER, 0)
+		errors.AssertGLError(errors.Normal, "render target depth attachment")
+	}
+
+	status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER)
+	gl.BindFramebuffer(gl.FRAMEBUFFER, mLoop.boundFramebuffer())
+	if status != gl.FRAMEBUFFER_COMPLETE {
+		return fmt.Errorf("render target framebuffer incomplete: 0x%x", status)
+	}
+	return nil
+}
+
+// Size returns the size of the target in pixels
+func (t *RenderTarget) Size() (int, int) {
+	return t.width, t.height
+}
+
+// Texture returns the color texture the target renders to
+func (t *RenderTarget) Texture() uint32 {
+	return t.colorTexture
+}
+
+// RegisterTexture adds the color texture to the texture cache, so groups can get it with GetTextureByID
+func (t *RenderTarget) RegisterTexture(id string) error {
+	if _, err := GetTextureByID(id); err == nil {
+		return fmt.Errorf("texture already registered: %v", id)
+	}
+	texCache[id] = t.colorTexture
+	t.textureID = id
+	return nil
+}
+
+// SetClearColor sets the color the target is cleared with before its render groups are rendered
+func (t *RenderTarget) SetClearColor(r, g, b, a float32) {
+	t.clearColor = [4]float32{r, g, b, a}
+}
+
+// Bind makes following rendering go to the target and sets the viewport to its size.
+// Viewport sized targets are resized first if the viewport has changed.
+// Cameras and normal matrices use the size of the target while it is bound.
+func (t *RenderTarget) Bind() {
+	t.fitViewport()
+	mLoop.boundTarget = t
+	if t.headless {
+		return
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, t.fbo)
+	gl.Viewport(0, 0, int32(t.width), int32(t.height))
+	errors.AssertGLError(errors.Normal, "bind render target")
+	// the camera projection uses the aspect ratio of the target
+	mLoop.updateCameraUniforms()
+}
+
+// Clear clears the target with its clear color. The target must be bound.
+func (t *RenderTarget) Clear() {
+	if t.headless {
+		return
+	}
+	gl.ClearColor(t.clearColor[0], t.clearColor[1], t.clearColor[2], t.clearColor[3])
+	// glClear respects the depth mask, which groups without depth testing leave off
+	gl.DepthMask(true)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+	gl.ClearColor(mLoop.clearColor[0], mLoop.clearColor[1], mLoop.clearColor[2], mLoop.clearColor[3])
+	errors.AssertGLError(errors.Normal, "clear render target")
+}
+
+// UnbindRenderTarget makes rendering go to the window again and restores the viewport.
+// Before post-processing has been applied, rendering goes to the frame being post-processed instead.
+func UnbindRenderTarget() {
+	if mLoop.frameTarget != nil {
+		mLoop.frameTarget.Bind()
+		return
+	}
+	mLoop.boundTarget = nil
+	if mLoop.headless {
+		return
+	}
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.Viewport(0, 0, int32(mLoop.width), int32(mLoop.height))
+	mLoop.updateCameraUniforms()
+}
+
+// Delete deletes the framebuffer and its attachments, and removes the texture from the texture cache
+func (t *RenderTarget) Delete() {
+	if t.textureID != "" {
+		if texture, ok := texCache[t.textureID]; ok && texture == t.colorTexture {
+			delete(texCache, t.textureID)
+		}
+		t.textureID = ""
+	}
+	if mLoop.boundTarget == t {
+		UnbindRenderTarget()
+	}
+	if t.headless {
+		return
+	}
+	if t.fbo != 0 {
+		gl.DeleteFramebuffers(1, &t.fbo)
+		gl.DeleteTextures(1, &t.colorTexture)
+		t.fbo, t.colorTexture = 0, 0
+	}
+	if t.depthBuffer != 0 {
+		gl.DeleteRenderbuffers(1, &t.depthBuffer)
+		t.depthBuffer = 0
+	}
+}
+
+// boundFramebuffer returns the framebuffer of the bound render target, 0 for the window
+func (c *Context) boundFramebuffer() uint32 {
+	if c.boundTarget != nil {
+		return c.boundTarget.fbo
+	}
+	return 0
+}
+
+// renderTargets returns the targets used by the render groups, in the order they are first used
+func (c *Context) renderTargets() []*RenderTarget {
+	var targets []*RenderTarget
+	seen := make(map[*RenderTarget]bool)
+	for _, id := range c.renderOrder {
+		if target := c.rendergroups[id].target; target != nil && !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
@@ -206,7 +206,7 @@ func (g *BasicRenderGroup2D) Render() {
 	}
 	gl.LineWidth(5)
 
-	normalMatrix := graphics.GetNormalMatrix(graphics.NormalMatrixOrthoOrigo)
+	normalMatrix := g.rg.GetProjectionMatrix()
 	//fmt.Printf("normalMatrix: %v", normalMatrix)
 	gl.UniformMatrix4fv(normalMatrixUniform, 1, false, &normalMatrix[0])
 	errors.AssertGLError(errors.Debug, fmt.Sprintf("normalMatrix: %v", textureUniform))
@@ -24,6 +24,7 @@ func getCachedShader(file string, shaderType uint32) uint32 {
 	if err != nil {
 		panic(err.Error())
 	}
+	shaderCache[file] = vertexShaderPgm
 	return vertexShaderPgm
 }
 
@@ -31,6 +32,7 @@ func clearShaderCache() {
 	for _, shader := range shaderCache {
 		gl.DeleteShader(shader)
 	}
+	shaderCache = make(map[string]uint32)
 }
 
 // compileShader compiles a shader program from a source string
@@ -0,0 +1,14 @@
+#version 330
+
+uniform sampler2D image;
+uniform sampler2D bloom;
+uniform float intensity;
+
+in vec2 fragTexCoord;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(image, fragTexCoord);
+    outputColor = vec4(color.rgb + texture(bloom, fragTexCoord).rgb * intensity, color.a);
+}
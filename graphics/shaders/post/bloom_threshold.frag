@@ -0,0 +1,16 @@
+#version 330
+
+uniform sampler2D image;
+// brightness above which pixels glow
+uniform float threshold;
+
+in vec2 fragTexCoord;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(image, fragTexCoord);
+    float brightness = dot(color.rgb, vec3(0.2126, 0.7152, 0.0722));
+    float glow = max(brightness - threshold, 0.0) / max(brightness, 0.0001);
+    outputColor = vec4(color.rgb * glow, 1);
+}
@@ -0,0 +1,24 @@
+#version 330
+
+uniform sampler2D image;
+uniform vec2 resolution;
+// (1, 0) for the horizontal pass, (0, 1) for the vertical pass
+uniform vec2 direction;
+// distance between the samples in pixels
+uniform float radius;
+
+in vec2 fragTexCoord;
+
+out vec4 outputColor;
+
+const float weights[5] = float[](0.227027, 0.1945946, 0.1216216, 0.054054, 0.016216);
+
+void main() {
+    vec2 offset = direction * radius / resolution;
+    vec4 color = texture(image, fragTexCoord) * weights[0];
+    for (int i = 1; i < 5; i++) {
+        color += texture(image, fragTexCoord + offset * float(i)) * weights[i];
+        color += texture(image, fragTexCoord - offset * float(i)) * weights[i];
+    }
+    outputColor = color;
+}
@@ -0,0 +1,29 @@
+#version 330
+
+uniform sampler2D image;
+// lutSize slices of lutSize x lutSize next to each other. Red grows to the right within a slice,
+// green downwards and blue from slice to slice.
+uniform sampler2D lut;
+uniform float lutSize;
+// how much of the graded color is used, 0-1
+uniform float intensity;
+
+in vec2 fragTexCoord;
+
+out vec4 outputColor;
+
+vec3 grade(vec3 color) {
+    float blue = color.b * (lutSize - 1.0);
+    float slice0 = floor(blue);
+    float slice1 = min(slice0 + 1.0, lutSize - 1.0);
+    vec2 texel = (color.rg * (lutSize - 1.0) + 0.5) / vec2(lutSize * lutSize, lutSize);
+    vec3 graded0 = texture(lut, texel + vec2(slice0 / lutSize, 0)).rgb;
+    vec3 graded1 = texture(lut, texel + vec2(slice1 / lutSize, 0)).rgb;
+    return mix(graded0, graded1, blue - slice0);
+}
+
+void main() {
+    vec4 color = texture(image, fragTexCoord);
+    vec3 clamped = clamp(color.rgb, 0.0, 1.0);
+    outputColor = vec4(mix(clamped, grade(clamped), intensity), color.a);
+}
@@ -0,0 +1,35 @@
+#version 330
+
+uniform sampler2D image;
+uniform vec2 resolution;
+// how much the screen bulges
+uniform float curvature;
+// how dark every other line is, 0-1
+uniform float scanlines;
+// how far apart the red and blue channels are, in texture coordinates
+uniform float aberration;
+
+in vec2 fragTexCoord;
+
+out vec4 outputColor;
+
+vec2 curve(vec2 uv) {
+    uv = uv * 2.0 - 1.0;
+    vec2 offset = abs(uv.yx) * curvature;
+    uv += uv * offset * offset;
+    return uv * 0.5 + 0.5;
+}
+
+void main() {
+    vec2 uv = curve(fragTexCoord);
+    if (uv.x < 0.0 || uv.x > 1.0 || uv.y < 0.0 || uv.y > 1.0) {
+        outputColor = vec4(0, 0, 0, 1);
+        return;
+    }
+    vec3 color = vec3(
+        texture(image, uv + vec2(aberration, 0)).r,
+        texture(image, uv).g,
+        texture(image, uv - vec2(aberration, 0)).b);
+    color *= 1.0 - scanlines * (0.5 + 0.5 * sin(uv.y * resolution.y * 3.14159));
+    outputColor = vec4(color, 1);
+}
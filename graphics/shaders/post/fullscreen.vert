@@ -0,0 +1,10 @@
+#version 330
+
+out vec2 fragTexCoord;
+
+// draws one triangle covering the screen, no vertex data needed
+void main() {
+    vec2 position = vec2((gl_VertexID << 1) & 2, gl_VertexID & 2);
+    fragTexCoord = position;
+    gl_Position = vec4(position * 2.0 - 1.0, 0, 1);
+}
@@ -0,0 +1,18 @@
+#version 330
+
+uniform sampler2D image;
+// how dark the corners get, 0-1
+uniform float intensity;
+// distance from the center where the darkening ends, and how long it fades
+uniform float radius;
+uniform float softness;
+
+in vec2 fragTexCoord;
+
+out vec4 outputColor;
+
+void main() {
+    vec4 color = texture(image, fragTexCoord);
+    float vignette = smoothstep(radius, radius - softness, distance(fragTexCoord, vec2(0.5)));
+    outputColor = vec4(color.rgb * mix(1.0, vignette, intensity), color.a);
+}
@@ -69,5 +69,13 @@ func RegisterTextureFromImage(id string, img *image.RGBA) (uint32, error) {
 		gl.UNSIGNED_BYTE,
 		gl.Ptr(img.Pix))
 
+	texCache[id] = texture
 	return texture, nil
 }
+
+func clearTextureCache() {
+	for _, texture := range texCache {
+		gl.DeleteTextures(1, &texture)
+	}
+	texCache = make(map[string]uint32)
+}
@@ -0,0 +1,628 @@
+package windows
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// BindingType is the kind of input a Binding listens to
+type BindingType int
+
+// Binding types
+const (
+	BindingKey           BindingType = iota
+	BindingMouseButton               = iota
+	BindingGamepadButton             = iota
+)
+
+// AnyJoystick makes a gamepad binding listen to all connected gamepads
+const AnyJoystick glfw.Joystick = -1
+
+// Binding binds a key, a mouse button or a gamepad button to an action.
+// Mods are modifier keys that must be held at the same time.
+// Bindings are written as strings like "Space", "ctrl+S", "shift+mouse:Left",
+// "gamepad:0" (any gamepad) or "gamepad2:0" (the second joystick) in config files.
+type Binding struct {
+	Type          BindingType
+	Key           glfw.Key
+	Button        glfw.MouseButton
+	Joystick      glfw.Joystick
+	GamepadButton int
+	Mods          glfw.ModifierKey
+}
+
+// KeyBinding returns a binding for a key
+func KeyBinding(key glfw.Key, mods glfw.ModifierKey) Binding {
+	return Binding{Type: BindingKey, Key: key, Mods: mods}
+}
+
+// MouseBinding returns a binding for a mouse button
+func MouseBinding(button glfw.MouseButton, mods glfw.ModifierKey) Binding {
+	return Binding{Type: BindingMouseButton, Button: button, Mods: mods}
+}
+
+// GamepadButtonBinding returns a binding for a gamepad button. Use AnyJoystick to listen to all gamepads.
+func GamepadButtonBinding(joy glfw.Joystick, button int) Binding {
+	return Binding{Type: BindingGamepadButton, Joystick: joy, GamepadButton: button}
+}
+
+// AxisBinding binds two inputs to an axis going from -1 (Negative held) to 1 (Positive held)
+type AxisBinding struct {
+	Negative Binding `json:"negative"`
+	Positive Binding `json:"positive"`
+}
+
+// GamepadAxisBinding binds an analog gamepad axis to an axis
+type GamepadAxisBinding struct {
+	Joystick glfw.Joystick `json:"joystick"`
+	Axis     int           `json:"axis"`
+	Invert   bool          `json:"invert,omitempty"`
+}
+
+type gamepadButton struct {
+	joystick glfw.Joystick
+	button   int
+}
+
+type actionState struct {
+	held, pressed, released bool
+}
+
+// ActionMap maps named actions and axes to inputs, so that scenes don't have to hard-code keys.
+// Pressed and Released are true from the frame the input happens until the end of the next tick.
+type ActionMap struct {
+	actions        map[string][]Binding
+	axes           map[string][]AxisBinding
+	gamepadAxes    map[string][]GamepadAxisBinding
+	states         map[string]*actionState
+	keys           map[glfw.Key]bool
+	buttons        map[glfw.MouseButton]bool
+	gamepadButtons map[gamepadButton]bool
+	gamepads       []GamepadState
+}
+
+// NewActionMap creates an ActionMap without any bindings
+func NewActionMap() *ActionMap {
+	m := new(ActionMap)
+	m.actions = make(map[string][]Binding)
+	m.axes = make(map[string][]AxisBinding)
+	m.gamepadAxes = make(map[string][]GamepadAxisBinding)
+	m.states = make(map[string]*actionState)
+	m.keys = make(map[glfw.Key]bool)
+	m.buttons = make(map[glfw.MouseButton]bool)
+	m.gamepadButtons = make(map[gamepadButton]bool)
+	return m
+}
+
+// Bind adds bindings to an action
+func (m *ActionMap) Bind(action string, bindings ...Binding) {
+	m.actions[action] = append(m.actions[action], bindings...)
+	if _, exists := m.states[action]; !exists {
+		m.states[action] = new(actionState)
+	}
+	// binding an input that is already held doesn't count as a press
+	m.states[action].held = m.actionHeld(action)
+}
+
+// Rebind replaces the bindings of an action
+func (m *ActionMap) Rebind(action string, bindings ...Binding) {
+	m.actions[action] = nil
+	m.Bind(action, bindings...)
+}
+
+// Unbind removes an action
+func (m *ActionMap) Unbind(action string) {
+	delete(m.actions, action)
+	delete(m.states, action)
+}
+
+// Bindings returns the bindings of an action
+func (m *ActionMap) Bindings(action string) []Binding {
+	return m.actions[action]
+}
+
+// BindAxis adds bindings to an axis
+func (m *ActionMap) BindAxis(axis string, bindings ...AxisBinding) {
+	m.axes[axis] = append(m.axes[axis], bindings...)
+}
+
+// RebindAxis replaces the bindings of an axis
+func (m *ActionMap) RebindAxis(axis string, bindings ...AxisBinding) {
+	m.axes[axis] = append([]AxisBinding(nil), bindings...)
+}
+
+// BindGamepadAxis adds analog gamepad bindings to an axis
+func (m *ActionMap) BindGamepadAxis(axis string, bindings ...GamepadAxisBinding) {
+	m.gamepadAxes[axis] = append(m.gamepadAxes[axis], bindings...)
+}
+
+// RebindGamepadAxis replaces the analog gamepad bindings of an axis
+func (m *ActionMap) RebindGamepadAxis(axis string, bindings ...GamepadAxisBinding) {
+	m.gamepadAxes[axis] = append([]GamepadAxisBinding(nil), bindings...)
+}
+
+// UnbindAxis removes all bindings of an axis
+func (m *ActionMap) UnbindAxis(axis string) {
+	delete(m.axes, axis)
+	delete(m.gamepadAxes, axis)
+}
+
+// AxisBindings returns the bindings of an axis
+func (m *ActionMap) AxisBindings(axis string) []AxisBinding {
+	return m.axes[axis]
+}
+
+// Held returns true while any input bound to the action is held
+func (m *ActionMap) Held(action string) bool {
+	if state, ok := m.states[action]; ok {
+		return state.held
+	}
+	return false
+}
+
+// Pressed returns true if the action started being held since the last tick
+func (m *ActionMap) Pressed(action string) bool {
+	if state, ok := m.states[action]; ok {
+		return state.pressed
+	}
+	return false
+}
+
+// Released returns true if the action stopped being held since the last tick
+func (m *ActionMap) Released(action string) bool {
+	if state, ok := m.states[action]; ok {
+		return state.released
+	}
+	return false
+}
+
+// Axis returns the value of an axis, from -1 to 1
+func (m *ActionMap) Axis(axis string) float32 {
+	var value float32
+	for _, binding := range m.axes[axis] {
+		if m.isHeld(binding.Positive) {
+			value++
+		}
+		if m.isHeld(binding.Negative) {
+			value--
+		}
+	}
+	for _, binding := range m.gamepadAxes[axis] {
+		for _, gamepad := range m.gamepads {
+			if binding.Joystick != AnyJoystick && binding.Joystick != gamepad.Joystick ||
+				binding.Axis >= len(gamepad.Axes) {
+				continue
+			}
+			if binding.Invert {
+				value -= gamepad.Axes[binding.Axis]
+			} else {
+				value += gamepad.Axes[binding.Axis]
+			}
+		}
+	}
+	return clampAxis(value)
+}
+
+func clampAxis(value float32) float32 {
+	if value > 1 {
+		return 1
+	} else if value < -1 {
+		return -1
+	}
+	return value
+}
+
+// mods returns the modifier keys that are currently held
+func (m *ActionMap) mods() glfw.ModifierKey {
+	var mods glfw.ModifierKey
+	if m.keys[glfw.KeyLeftShift] || m.keys[glfw.KeyRightShift] {
+		mods |= glfw.ModShift
+	}
+	if m.keys[glfw.KeyLeftControl] || m.keys[glfw.KeyRightControl] {
+		mods |= glfw.ModControl
+	}
+	if m.keys[glfw.KeyLeftAlt] || m.keys[glfw.KeyRightAlt] {
+		mods |= glfw.ModAlt
+	}
+	if m.keys[glfw.KeyLeftSuper] || m.keys[glfw.KeyRightSuper] {
+		mods |= glfw.ModSuper
+	}
+	return mods
+}
+
+func (m *ActionMap) isHeld(b Binding) bool {
+	if b.Mods != 0 && m.mods()&b.Mods != b.Mods {
+		return false
+	}
+	switch b.Type {
+	case BindingKey:
+		return m.keys[b.Key]
+	case BindingMouseButton:
+		return m.buttons[b.Button]
+	case BindingGamepadButton:
+		if b.Joystick != AnyJoystick {
+			return m.gamepadButtons[gamepadButton{b.Joystick, b.GamepadButton}]
+		}
+		for button, held := range m.gamepadButtons {
+			if held && button.button == b.GamepadButton {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *ActionMap) actionHeld(action string) bool {
+	for _, binding := range m.actions[action] {
+		if m.isHeld(binding) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *ActionMap) updateAction(action string) {
+	state := m.states[action]
+	held := m.actionHeld(action)
+	if held && !state.held {
+		state.pressed = true
+	} else if !held && state.held {
+		state.released = true
+	}
+	state.held = held
+}
+
+func (m *ActionMap) updateActions() {
+	for action := range m.actions {
+		m.updateAction(action)
+	}
+}
+
+// actionMapState is a saved copy of the input state of an action map
+type actionMapState struct {
+	states         map[string]actionState
+	keys           map[glfw.Key]bool
+	buttons        map[glfw.MouseButton]bool
+	gamepadButtons map[gamepadButton]bool
+}
+
+// save returns a copy of the input state
+func (m *ActionMap) save() actionMapState {
+	saved := actionMapState{
+		states:         make(map[string]actionState, len(m.states)),
+		keys:           make(map[glfw.Key]bool, len(m.keys)),
+		buttons:        make(map[glfw.MouseButton]bool, len(m.buttons)),
+		gamepadButtons: make(map[gamepadButton]bool, len(m.gamepadButtons)),
+	}
+	for action, state := range m.states {
+		saved.states[action] = *state
+	}
+	for key, held := range m.keys {
+		saved.keys[key] = held
+	}
+	for button, held := range m.buttons {
+		saved.buttons[button] = held
+	}
+	for button, held := range m.gamepadButtons {
+		saved.gamepadButtons[button] = held
+	}
+	return saved
+}
+
+// restore puts back a saved input state. Actions bound since it was saved are left as they are.
+func (m *ActionMap) restore(saved actionMapState) {
+	for action, state := range m.states {
+		if savedState, ok := saved.states[action]; ok {
+			*state = savedState
+		}
+	}
+	m.keys = saved.keys
+	m.buttons = saved.buttons
+	m.gamepadButtons = saved.gamepadButtons
+}
+
+// update applies the input events of a frame
+func (m *ActionMap) update(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) {
+	// apply the events one by one so that presses and releases within a frame aren't lost
+	for _, event := range keyEvents {
+		m.keys[event.Key] = event.Action != glfw.Release
+		m.updateActions()
+	}
+	for _, event := range mouseEvents {
+		m.buttons[event.Button] = event.Action != glfw.Release
+		m.updateActions()
+	}
+}
+
+// updateGamepads applies the gamepad events and state of a frame
+func (m *ActionMap) updateGamepads(buttonEvents []GamepadButtonEvent, gamepads []GamepadState) {
+	for _, event := range buttonEvents {
+		m.gamepadButtons[gamepadButton{event.Joystick, event.Button}] = event.Action != glfw.Release
+		m.updateActions()
+	}
+	m.gamepads = gamepads
+}
+
+// endTick clears the pressed and released flags after a tick
+func (m *ActionMap) endTick() {
+	for _, state := range m.states {
+		state.pressed = false
+		state.released = false
+	}
+}
+
+type actionMapConfig struct {
+	Actions     map[string][]Binding            `json:"actions"`
+	Axes        map[string][]AxisBinding        `json:"axes"`
+	GamepadAxes map[string][]GamepadAxisBinding `json:"gamepadAxes,omitempty"`
+}
+
+// Save writes the bindings as JSON
+func (m *ActionMap) Save(out io.Writer) error {
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(actionMapConfig{m.actions, m.axes, m.gamepadAxes})
+}
+
+// Load replaces all bindings with the ones read from JSON.
+// The state of inputs that are currently held is kept.
+func (m *ActionMap) Load(in io.Reader) error {
+	var config actionMapConfig
+	if err := json.NewDecoder(in).Decode(&config); err != nil {
+		return err
+	}
+	m.actions = make(map[string][]Binding)
+	m.axes = make(map[string][]AxisBinding)
+	m.gamepadAxes = make(map[string][]GamepadAxisBinding)
+	m.states = make(map[string]*actionState)
+	for action, bindings := range config.Actions {
+		m.Bind(action, bindings...)
+	}
+	for axis, bindings := range config.Axes {
+		m.BindAxis(axis, bindings...)
+	}
+	for axis, bindings := range config.GamepadAxes {
+		m.BindGamepadAxis(axis, bindings...)
+	}
+	return nil
+}
+
+// SaveFile writes the bindings to a file
+func (m *ActionMap) SaveFile(file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := m.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadFile replaces all bindings with the ones in a file
+func (m *ActionMap) LoadFile(file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.Load(f)
+}
+
+var modNames = []struct {
+	mod  glfw.ModifierKey
+	name string
+}{
+	{glfw.ModControl, "ctrl"},
+	{glfw.ModAlt, "alt"},
+	{glfw.ModShift, "shift"},
+	{glfw.ModSuper, "super"},
+}
+
+var mouseButtonNames = map[glfw.MouseButton]string{
+	glfw.MouseButtonLeft:   "Left",
+	glfw.MouseButtonRight:  "Right",
+	glfw.MouseButtonMiddle: "Middle",
+}
+
+var keyNames = map[glfw.Key]string{
+	glfw.KeySpace:        "Space",
+	glfw.KeyApostrophe:   "Apostrophe",
+	glfw.KeyComma:        "Comma",
+	glfw.KeyMinus:        "Minus",
+	glfw.KeyPeriod:       "Period",
+	glfw.KeySlash:        "Slash",
+	glfw.KeySemicolon:    "Semicolon",
+	glfw.KeyEqual:        "Equal",
+	glfw.KeyLeftBracket:  "LeftBracket",
+	glfw.KeyBackslash:    "Backslash",
+	glfw.KeyRightBracket: "RightBracket",
+	glfw.KeyGraveAccent:  "GraveAccent",
+	glfw.KeyEscape:       "Escape",
+	glfw.KeyEnter:        "Enter",
+	glfw.KeyTab:          "Tab",
+	glfw.KeyBackspace:    "Backspace",
+	glfw.KeyInsert:       "Insert",
+	glfw.KeyDelete:       "Delete",
+	glfw.KeyRight:        "Right",
+	glfw.KeyLeft:         "Left",
+	glfw.KeyDown:         "Down",
+	glfw.KeyUp:           "Up",
+	glfw.KeyPageUp:       "PageUp",
+	glfw.KeyPageDown:     "PageDown",
+	glfw.KeyHome:         "Home",
+	glfw.KeyEnd:          "End",
+	glfw.KeyCapsLock:     "CapsLock",
+	glfw.KeyScrollLock:   "ScrollLock",
+	glfw.KeyNumLock:      "NumLock",
+	glfw.KeyPrintScreen:  "PrintScreen",
+	glfw.KeyPause:        "Pause",
+	glfw.KeyKPDecimal:    "KPDecimal",
+	glfw.KeyKPDivide:     "KPDivide",
+	glfw.KeyKPMultiply:   "KPMultiply",
+	glfw.KeyKPSubtract:   "KPSubtract",
+	glfw.KeyKPAdd:        "KPAdd",
+	glfw.KeyKPEnter:      "KPEnter",
+	glfw.KeyKPEqual:      "KPEqual",
+	glfw.KeyLeftShift:    "LeftShift",
+	glfw.KeyLeftControl:  "LeftControl",
+	glfw.KeyLeftAlt:      "LeftAlt",
+	glfw.KeyLeftSuper:    "LeftSuper",
+	glfw.KeyRightShift:   "RightShift",
+	glfw.KeyRightControl: "RightControl",
+	glfw.KeyRightAlt:     "RightAlt",
+	glfw.KeyRightSuper:   "RightSuper",
+	glfw.KeyMenu:         "Menu",
+}
+
+var keysByName = make(map[string]glfw.Key)
+
+func init() {
+	// GLFW uses ASCII for letters and digits, and consecutive values for function and keypad keys
+	for i := 0; i < 26; i++ {
+		keyNames[glfw.KeyA+glfw.Key(i)] = string(rune('A' + i))
+	}
+	for i := 0; i < 10; i++ {
+		keyNames[glfw.Key0+glfw.Key(i)] = strconv.Itoa(i)
+		keyNames[glfw.KeyKP0+glfw.Key(i)] = "KP" + strconv.Itoa(i)
+	}
+	for i := 0; i < 25; i++ {
+		keyNames[glfw.KeyF1+glfw.Key(i)] = "F" + strconv.Itoa(i+1)
+	}
+	for key, name := range keyNames {
+		keysByName[strings.ToLower(name)] = key
+	}
+}
+
+// String returns the binding in config file format
+func (b Binding) String() string {
+	parts := make([]string, 0, 5)
+	for _, mod := range modNames {
+		if b.Mods&mod.mod != 0 {
+			parts = append(parts, mod.name)
+		}
+	}
+	switch b.Type {
+	case BindingKey:
+		if name, ok := keyNames[b.Key]; ok {
+			parts = append(parts, name)
+		} else {
+			parts = append(parts, "key:"+strconv.Itoa(int(b.Key)))
+		}
+	case BindingMouseButton:
+		if name, ok := mouseButtonNames[b.Button]; ok {
+			parts = append(parts, "mouse:"+name)
+		} else {
+			parts = append(parts, "mouse:"+strconv.Itoa(int(b.Button)+1))
+		}
+	case BindingGamepadButton:
+		if b.Joystick == AnyJoystick {
+			parts = append(parts, "gamepad:"+strconv.Itoa(b.GamepadButton))
+		} else {
+			parts = append(parts, "gamepad"+strconv.Itoa(int(b.Joystick)+1)+":"+strconv.Itoa(b.GamepadButton))
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// ParseBinding parses a binding in config file format, e.g. "ctrl+S" or "mouse:Left"
+func ParseBinding(s string) (Binding, error) {
+	var b Binding
+	parts := strings.Split(s, "+")
+	for _, part := range parts[:len(parts)-1] {
+		found := false
+		for _, mod := range modNames {
+			if strings.EqualFold(part, mod.name) {
+				b.Mods |= mod.mod
+				found = true
+			}
+		}
+		if !found {
+			return b, fmt.Errorf("unknown modifier %q in binding %q", part, s)
+		}
+	}
+
+	input := strings.ToLower(parts[len(parts)-1])
+	switch {
+	case strings.HasPrefix(input, "mouse:"):
+		b.Type = BindingMouseButton
+		name := strings.TrimPrefix(input, "mouse:")
+		for button, buttonName := range mouseButtonNames {
+			if strings.EqualFold(name, buttonName) {
+				b.Button = button
+				return b, nil
+			}
+		}
+		number, err := strconv.Atoi(name)
+		if err != nil || number < 1 || glfw.MouseButton(number-1) > glfw.MouseButtonLast {
+			return b, fmt.Errorf("unknown mouse button in binding %q", s)
+		}
+		b.Button = glfw.MouseButton(number - 1)
+	case strings.HasPrefix(input, "gamepad"):
+		b.Type = BindingGamepadButton
+		separator := strings.Index(input, ":")
+		if separator < 0 {
+			return b, fmt.Errorf("missing gamepad button in binding %q", s)
+		}
+		b.Joystick = AnyJoystick
+		if joystick := input[len("gamepad"):separator]; joystick != "" {
+			number, err := strconv.Atoi(joystick)
+			if err != nil || number < 1 || glfw.Joystick(number-1) > glfw.JoystickLast {
+				return b, fmt.Errorf("invalid joystick in binding %q", s)
+			}
+			b.Joystick = glfw.Joystick(number - 1)
+		}
+		button, err := strconv.Atoi(input[separator+1:])
+		if err != nil || button < 0 {
+			return b, fmt.Errorf("invalid gamepad button in binding %q", s)
+		}
+		b.GamepadButton = button
+	case strings.HasPrefix(input, "key:"):
+		b.Type = BindingKey
+		number, err := strconv.Atoi(strings.TrimPrefix(input, "key:"))
+		if err != nil {
+			return b, fmt.Errorf("invalid key code in binding %q", s)
+		}
+		b.Key = glfw.Key(number)
+	default:
+		b.Type = BindingKey
+		key, ok := keysByName[input]
+		if !ok {
+			return b, fmt.Errorf("unknown key in binding %q", s)
+		}
+		b.Key = key
+	}
+	return b, nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (b Binding) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (b *Binding) UnmarshalText(text []byte) error {
+	parsed, err := ParseBinding(string(text))
+	if err != nil {
+		return err
+	}
+	*b = parsed
+	return nil
+}
+
+// Actions returns the action map of the window
+func (w *Window) Actions() *ActionMap {
+	return w.actions
+}
+
+// Actions returns the action map of the main window
+func Actions() *ActionMap {
+	return MainWindow.actions
+}
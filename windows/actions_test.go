@@ -0,0 +1,146 @@
+package windows
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func keyEvent(key glfw.Key, action glfw.Action) KeyboardInputEvent {
+	return KeyboardInputEvent{Key: key, Action: action}
+}
+
+func TestActionPressedHeldReleased(t *testing.T) {
+	m := NewActionMap()
+	m.Bind("jump", KeyBinding(glfw.KeySpace, 0), MouseBinding(glfw.MouseButtonLeft, 0))
+
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeySpace, glfw.Press)}, nil)
+	if !m.Pressed("jump") || !m.Held("jump") || m.Released("jump") {
+		t.Errorf("jump should be pressed and held")
+	}
+	m.endTick()
+	if m.Pressed("jump") || !m.Held("jump") {
+		t.Errorf("jump should only be held after the tick")
+	}
+
+	// the mouse button keeps the action held when the key is released
+	m.update(nil, []MouseInputEvent{{Button: glfw.MouseButtonLeft, Action: glfw.Press}})
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeySpace, glfw.Release)}, nil)
+	if !m.Held("jump") || m.Released("jump") {
+		t.Errorf("jump should still be held by the mouse button")
+	}
+	m.endTick()
+
+	// pressing and releasing within one frame is not lost
+	m.update(nil, []MouseInputEvent{{Button: glfw.MouseButtonLeft, Action: glfw.Release}})
+	m.endTick()
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeySpace, glfw.Press), keyEvent(glfw.KeySpace, glfw.Release)}, nil)
+	if !m.Pressed("jump") || !m.Released("jump") || m.Held("jump") {
+		t.Errorf("a quick tap should be both pressed and released")
+	}
+}
+
+func TestActionModifiers(t *testing.T) {
+	m := NewActionMap()
+	m.Bind("save", KeyBinding(glfw.KeyS, glfw.ModControl))
+
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeyS, glfw.Press)}, nil)
+	if m.Held("save") {
+		t.Errorf("save shouldn't trigger without ctrl")
+	}
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeyRightControl, glfw.Press)}, nil)
+	if !m.Pressed("save") {
+		t.Errorf("save should trigger with ctrl held")
+	}
+}
+
+func TestActionAxisAndRebind(t *testing.T) {
+	m := NewActionMap()
+	m.BindAxis("horizontal", AxisBinding{KeyBinding(glfw.KeyLeft, 0), KeyBinding(glfw.KeyRight, 0)})
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeyLeft, glfw.Press)}, nil)
+	if m.Axis("horizontal") != -1 {
+		t.Errorf("expected -1, got %v", m.Axis("horizontal"))
+	}
+
+	m.RebindAxis("horizontal", AxisBinding{KeyBinding(glfw.KeyA, 0), KeyBinding(glfw.KeyD, 0)})
+	if m.Axis("horizontal") != 0 {
+		t.Errorf("old binding should be gone, got %v", m.Axis("horizontal"))
+	}
+	m.update([]KeyboardInputEvent{keyEvent(glfw.KeyD, glfw.Press)}, nil)
+	if m.Axis("horizontal") != 1 {
+		t.Errorf("expected 1, got %v", m.Axis("horizontal"))
+	}
+}
+
+func TestActionMapSaveLoad(t *testing.T) {
+	m := NewActionMap()
+	m.Bind("jump", KeyBinding(glfw.KeySpace, 0), MouseBinding(glfw.MouseButton5, glfw.ModShift))
+	m.Bind("save", KeyBinding(glfw.KeyS, glfw.ModControl|glfw.ModAlt))
+	m.Bind("odd", KeyBinding(glfw.KeyWorld1, 0), KeyBinding(glfw.KeyF12, 0))
+	m.Bind("fire", GamepadButtonBinding(AnyJoystick, 2), GamepadButtonBinding(glfw.Joystick2, 0))
+	m.BindAxis("horizontal", AxisBinding{KeyBinding(glfw.KeyLeft, 0), KeyBinding(glfw.KeyRight, 0)})
+	m.BindGamepadAxis("horizontal", GamepadAxisBinding{Joystick: AnyJoystick, Axis: 1, Invert: true})
+
+	buffer := new(bytes.Buffer)
+	if err := m.Save(buffer); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewActionMap()
+	if err := loaded.Load(buffer); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.actions, loaded.actions) || !reflect.DeepEqual(m.axes, loaded.axes) ||
+		!reflect.DeepEqual(m.gamepadAxes, loaded.gamepadAxes) {
+		t.Errorf("loaded bindings differ.\nsaved: %v %v %v\nloaded: %v %v %v",
+			m.actions, m.axes, m.gamepadAxes, loaded.actions, loaded.axes, loaded.gamepadAxes)
+	}
+}
+
+func TestParseBinding(t *testing.T) {
+	b, err := ParseBinding("ctrl+shift+mouse:Right")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != MouseBinding(glfw.MouseButtonRight, glfw.ModControl|glfw.ModShift) {
+		t.Errorf("parsed wrong binding: %v", b)
+	}
+	if _, err := ParseBinding("hyper+A"); err == nil {
+		t.Errorf("expected an error for an unknown modifier")
+	}
+	if _, err := ParseBinding("NoSuchKey"); err == nil {
+		t.Errorf("expected an error for an unknown key")
+	}
+}
+
+// actionInputTestScene records whether an action is pressed when it handles input
+type actionInputTestScene struct {
+	stackTestScene
+	window  *Window
+	pressed []bool
+}
+
+func (s *actionInputTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	s.pressed = append(s.pressed, s.window.Actions().Pressed("jump"))
+	return WindowActionNone
+}
+
+func TestActionPressedInHandleInput(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	w.Actions().Bind("jump", KeyBinding(glfw.KeySpace, 0))
+	scene := &actionInputTestScene{window: w}
+	w.AddScene("test", scene)
+	w.initScenes()
+
+	w.keyQueue = append(w.keyQueue, keyEvent(glfw.KeySpace, glfw.Press))
+	w.drainInput()
+	w.dispatchInput()
+	w.tick(0.016)
+	w.drainInput()
+	w.dispatchInput()
+
+	if len(scene.pressed) != 2 || !scene.pressed[0] || scene.pressed[1] {
+		t.Errorf("the action should be pressed in HandleInput of the frame it happens, got %v", scene.pressed)
+	}
+}
@@ -0,0 +1,31 @@
+package windows
+
+import (
+	"errors"
+)
+
+// GetClipboard returns the text on the clipboard
+func (w *Window) GetClipboard() (string, error) {
+	if w.window == nil {
+		return "", errors.New("the window isn't open")
+	}
+	return w.window.GetClipboardString()
+}
+
+// SetClipboard puts text on the clipboard
+func (w *Window) SetClipboard(text string) {
+	if w.window == nil {
+		return
+	}
+	w.window.SetClipboardString(text)
+}
+
+// GetClipboard returns the text on the clipboard, using the main window
+func GetClipboard() (string, error) {
+	return MainWindow.GetClipboard()
+}
+
+// SetClipboard puts text on the clipboard, using the main window
+func SetClipboard(text string) {
+	MainWindow.SetClipboard(text)
+}
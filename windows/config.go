@@ -0,0 +1,98 @@
+package windows
+
+// DisplayMode is how a window is shown on its monitor
+type DisplayMode int
+
+// Display modes
+const (
+	// DisplayWindowed is a normal window with decorations
+	DisplayWindowed DisplayMode = iota
+	// DisplayFullscreen switches the monitor to the size of the window
+	DisplayFullscreen
+	// DisplayBorderless covers the monitor without changing its video mode
+	DisplayBorderless
+)
+
+// Config describes a window. Samples and Resizable only take effect when the window is opened,
+// the rest can also be changed at runtime with SetConfig.
+type Config struct {
+	Title string
+	// The size of the window, or the resolution in DisplayFullscreen
+	Width, Height int
+	DisplayMode   DisplayMode
+	// Index of the monitor used for fullscreen and borderless modes. 0 is the primary monitor.
+	Monitor int
+	VSync   bool
+	// MSAA samples, 0 disables multisampling
+	Samples   int
+	Resizable bool
+	// Size limits of a windowed window. 0 means no limit.
+	MinWidth, MinHeight int
+	MaxWidth, MaxHeight int
+}
+
+// DefaultConfig returns the config used by Init: a resizable 800x600 window with vsync
+func DefaultConfig() Config {
+	return Config{
+		Title:       "Testing",
+		Width:       800,
+		Height:      600,
+		DisplayMode: DisplayWindowed,
+		VSync:       true,
+		Resizable:   true,
+	}
+}
+
+// InitWithConfig configures and creates the main window
+func InitWithConfig(config Config) {
+	MainWindow.SetConfig(config)
+	MainWindow.Open()
+}
+
+// Config returns the config of the window
+func (w *Window) Config() Config {
+	return w.config
+}
+
+// SetConfig sets the config of the window. When the window is open the changes are applied
+// to it, keeping its GL context so render groups and textures stay valid. The context that was
+// current before is current again afterwards.
+func (w *Window) SetConfig(config Config) {
+	if w.window != nil {
+		restore := w.useContext()
+		w.window.ApplyConfig(w.config, config)
+		restore()
+	}
+	w.config = config
+}
+
+// SetDisplayMode switches between windowed, fullscreen and borderless mode
+func (w *Window) SetDisplayMode(mode DisplayMode) {
+	config := w.config
+	config.DisplayMode = mode
+	w.SetConfig(config)
+}
+
+// SetVSync turns vsync on or off
+func (w *Window) SetVSync(vsync bool) {
+	config := w.config
+	config.VSync = vsync
+	w.SetConfig(config)
+}
+
+// SetTitle sets the window title
+func (w *Window) SetTitle(title string) {
+	config := w.config
+	config.Title = title
+	w.SetConfig(config)
+}
+
+// SetDisplayMode switches the main window between windowed, fullscreen and borderless mode
+func SetDisplayMode(mode DisplayMode) {
+	MainWindow.SetDisplayMode(mode)
+}
+
+// SetVSync turns vsync of the main window on or off
+func SetVSync(vsync bool) {
+	MainWindow.SetVSync(vsync)
+}
@@ -0,0 +1,63 @@
+package windows
+
+import (
+	"testing"
+
+	"github.com/krapulacoders/krapulaengine2/graphics"
+)
+
+func TestNewWindowConfig(t *testing.T) {
+	w := NewWindow(320, 240, "small")
+	config := w.Config()
+	if config.Width != 320 || config.Height != 240 || config.Title != "small" {
+		t.Errorf("expected the given size and title, got %v", config)
+	}
+	if !config.VSync || !config.Resizable || config.DisplayMode != DisplayWindowed {
+		t.Errorf("expected the other values from the default config, got %v", config)
+	}
+}
+
+func TestSetConfigKeepsContext(t *testing.T) {
+	SetPlatform(NewHeadlessPlatform())
+	defer SetPlatform(glfwPlatform{})
+
+	w := NewWindow(320, 240, "config")
+	w.Open()
+	defer w.Close()
+	context := w.graphics
+
+	config := w.Config()
+	config.Width, config.Height = 640, 480
+	config.DisplayMode = DisplayFullscreen
+	w.SetConfig(config)
+
+	if width, height := w.GetSize(); width != 640 || height != 480 {
+		t.Errorf("expected the new size, got %vx%v", width, height)
+	}
+	if w.graphics != context || w.window.Graphics() != context {
+		t.Errorf("changing the config shouldn't replace the graphics context")
+	}
+	if w.Config().DisplayMode != DisplayFullscreen {
+		t.Errorf("expected the new display mode, got %v", w.Config().DisplayMode)
+	}
+}
+
+func TestSetConfigRestoresCurrentContext(t *testing.T) {
+	SetPlatform(NewHeadlessPlatform())
+	defer SetPlatform(glfwPlatform{})
+
+	first := NewWindow(320, 240, "first")
+	first.Open()
+	defer first.Close()
+	second := NewWindow(320, 240, "second")
+	second.Open()
+	defer second.Close()
+
+	first.makeCurrent()
+	config := second.Config()
+	config.Title = "renamed"
+	second.SetConfig(config)
+	if graphics.CurrentContext() != first.graphics {
+		t.Errorf("the context of the first window should be current again")
+	}
+}
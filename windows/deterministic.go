@@ -0,0 +1,92 @@
+package windows
+
+import (
+	"math/rand"
+	"time"
+)
+
+// DeterministicConfig makes the main loop run the same way every time. Together with
+// a recorded input replay the scenes end up in exactly the same state after MaxFrames frames.
+type DeterministicConfig struct {
+	// FrameTime is how far the virtual clock advances each frame, in seconds
+	FrameTime float64
+	// Seed of the engine RNG returned by Rand
+	Seed int64
+	// MaxFrames stops the main loop after this many frames. 0 runs until the main window is closed.
+	MaxFrames int
+}
+
+// deterministicMode is the virtual clock of a deterministic main loop
+type deterministicMode struct {
+	config DeterministicConfig
+	time   float64
+	frames int
+}
+
+// the deterministic mode of the main loop, nil when it runs on real time
+var deterministic *deterministicMode
+
+// the engine RNG
+var engineRand = rand.New(rand.NewSource(time.Now().UnixNano()))
+
+// SetDeterministic makes the main loop use a virtual clock advancing config.FrameTime every frame
+// and seeds the engine RNG with config.Seed. The clock and the RNG are reset every time MainLoop is started.
+// The target FPS limiter is ignored, frames are run as fast as possible.
+func SetDeterministic(config DeterministicConfig) {
+	if config.FrameTime <= 0 {
+		panic("deterministic frame time must be positive")
+	}
+	deterministic = &deterministicMode{config: config}
+	engineRand.Seed(config.Seed)
+}
+
+// ClearDeterministic makes the main loop run on real time again
+func ClearDeterministic() {
+	deterministic = nil
+}
+
+// IsDeterministic returns true if the main loop runs on a virtual clock
+func IsDeterministic() bool {
+	return deterministic != nil
+}
+
+// Rand returns the engine RNG. Scenes should use it instead of the global math/rand functions,
+// so their randomness is reproducible in deterministic mode. It may only be used from the main thread.
+func Rand() *rand.Rand {
+	return engineRand
+}
+
+// LoopTime returns the time of the main loop clock in seconds: the virtual clock
+// in deterministic mode, otherwise the platform clock.
+func LoopTime() float64 {
+	if deterministic != nil {
+		return deterministic.time
+	}
+	return platform.Time()
+}
+
+// LoopFrames returns the number of frames the main loop has run
+func LoopFrames() int {
+	if deterministic != nil {
+		return deterministic.frames
+	}
+	return MainWindow.frameStats.stats.Frames
+}
+
+// start resets the virtual clock and the engine RNG
+func (d *deterministicMode) start() {
+	d.time = 0
+	d.frames = 0
+	engineRand.Seed(d.config.Seed)
+}
+
+// nextFrame advances the virtual clock by one frame
+func (d *deterministicMode) nextFrame() {
+	d.time += d.config.FrameTime
+	d.frames++
+}
+
+// done returns true when the frame limit has been reached
+func (d *deterministicMode) done() bool {
+	return d.config.MaxFrames > 0 && d.frames >= d.config.MaxFrames
+}
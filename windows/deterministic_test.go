@@ -0,0 +1,94 @@
+package windows
+
+import (
+	"reflect"
+	"testing"
+)
+
+type randomTestScene struct {
+	headlessTestScene
+	values []int
+}
+
+func (s *randomTestScene) Tick(timedelta float64, keyStates []bool) {
+	s.headlessTestScene.Tick(timedelta, keyStates)
+	s.values = append(s.values, Rand().Intn(1000))
+}
+
+func runDeterministic(t *testing.T, config DeterministicConfig) *randomTestScene {
+	SetPlatform(NewHeadlessPlatform())
+	defer SetPlatform(glfwPlatform{})
+	SetDeterministic(config)
+	defer ClearDeterministic()
+
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "deterministic")
+	defer func() { MainWindow = main }()
+
+	scene := new(randomTestScene)
+	MainWindow.AddScene("test", scene)
+	MainWindow.SetTargetFPS(30)
+	MainLoop()
+
+	if LoopFrames() != config.MaxFrames {
+		t.Errorf("expected the loop to stop after %v frames, got %v", config.MaxFrames, LoopFrames())
+	}
+	return scene
+}
+
+func TestDeterministicMainLoop(t *testing.T) {
+	config := DeterministicConfig{FrameTime: 0.25, Seed: 42, MaxFrames: 8}
+	first := runDeterministic(t, config)
+	second := runDeterministic(t, config)
+
+	if len(first.timedeltas) != 8 {
+		t.Fatalf("expected 8 ticks, got %v", first.timedeltas)
+	}
+	for _, timedelta := range first.timedeltas {
+		if timedelta != 0.25 {
+			t.Errorf("expected the virtual frame time, got %v", timedelta)
+		}
+	}
+	if !reflect.DeepEqual(first.values, second.values) {
+		t.Errorf("the same seed should give the same values, got %v and %v", first.values, second.values)
+	}
+
+	config.Seed = 43
+	other := runDeterministic(t, config)
+	if reflect.DeepEqual(first.values, other.values) {
+		t.Errorf("a different seed should give different values")
+	}
+}
+
+// randomInitTestScene draws a value from the engine RNG when it's inited
+type randomInitTestScene struct {
+	stackTestScene
+	value int
+}
+
+func (s *randomInitTestScene) Init() {
+	s.value = Rand().Intn(1000000)
+	s.stackTestScene.Init()
+}
+
+func TestDeterministicSceneInitOrder(t *testing.T) {
+	SetDeterministic(DeterministicConfig{FrameTime: 0.25, Seed: 7})
+	defer ClearDeterministic()
+
+	var expected [2]int
+	for run := 0; run < 20; run++ {
+		Rand().Seed(7)
+		w := NewWindow(800, 600, "test")
+		first, second := new(randomInitTestScene), new(randomInitTestScene)
+		w.AddScene("first", first)
+		w.AddScene("second", second)
+		w.initScenes()
+
+		values := [2]int{first.value, second.value}
+		if run == 0 {
+			expected = values
+		} else if values != expected {
+			t.Fatalf("run %v: scenes should draw the same values every run, expected %v, got %v", run, expected, values)
+		}
+	}
+}
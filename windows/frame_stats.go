@@ -0,0 +1,107 @@
+package windows
+
+import (
+	"sort"
+)
+
+// FrameStatsWindowSize is the number of frames the frame time percentiles are calculated over
+const FrameStatsWindowSize = 120
+
+// how much of the newest frame goes into the smoothed FPS
+const fpsSmoothing = 0.1
+
+// FrameStats describes how long the frames of a window take. All times are in seconds.
+type FrameStats struct {
+	// Number of frames run
+	Frames int
+	// Time since the previous frame, and the FPS that would give
+	FrameTime float64
+	FPS       float64
+	// FPS smoothed over the last frames, better for displaying
+	SmoothedFPS float64
+	// Frame time percentiles over the last FrameStatsWindowSize frames
+	FrameTimeP50, FrameTimeP95, FrameTimeP99 float64
+
+	// Time spent on the phases of the last frame
+	InputTime, TickTime, RenderTime, SwapTime float64
+}
+
+// frameStats collects the timings of a window
+type frameStats struct {
+	stats      FrameStats
+	frameTimes []float64
+	next       int
+}
+
+func newFrameStats() *frameStats {
+	s := new(frameStats)
+	s.frameTimes = make([]float64, 0, FrameStatsWindowSize)
+	return s
+}
+
+// addFrame adds the frame time of a frame, before its phases are timed
+func (s *frameStats) addFrame(frameTime float64) {
+	s.stats.Frames++
+	s.stats.FrameTime = frameTime
+	if frameTime > 0 {
+		s.stats.FPS = 1 / frameTime
+		if s.stats.SmoothedFPS == 0 {
+			s.stats.SmoothedFPS = s.stats.FPS
+		} else {
+			s.stats.SmoothedFPS += (s.stats.FPS - s.stats.SmoothedFPS) * fpsSmoothing
+		}
+	}
+
+	if len(s.frameTimes) < FrameStatsWindowSize {
+		s.frameTimes = append(s.frameTimes, frameTime)
+	} else {
+		s.frameTimes[s.next] = frameTime
+		s.next = (s.next + 1) % FrameStatsWindowSize
+	}
+
+	sorted := append([]float64(nil), s.frameTimes...)
+	sort.Float64s(sorted)
+	s.stats.FrameTimeP50 = percentile(sorted, 0.50)
+	s.stats.FrameTimeP95 = percentile(sorted, 0.95)
+	s.stats.FrameTimeP99 = percentile(sorted, 0.99)
+}
+
+// percentile returns the value below which the fraction p of the sorted values fall
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	} else if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// FrameStats returns the frame timings of the window
+func (w *Window) FrameStats() FrameStats {
+	return w.frameStats.stats
+}
+
+// SetTargetFPS limits how many frames per second the main loop runs when set on the main window.
+// It works with vsync on or off. 0 removes the limit.
+func (w *Window) SetTargetFPS(fps float64) {
+	w.targetFPS = fps
+}
+
+// GetTargetFPS returns the FPS limit, 0 if there is none
+func (w *Window) GetTargetFPS() float64 {
+	return w.targetFPS
+}
+
+// GetFrameStats returns the frame timings of the main window
+func GetFrameStats() FrameStats {
+	return MainWindow.FrameStats()
+}
+
+// SetTargetFPS limits how many frames per second the main loop runs. 0 removes the limit.
+func SetTargetFPS(fps float64) {
+	MainWindow.SetTargetFPS(fps)
+}
@@ -0,0 +1,53 @@
+package windows
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTargetFPS(t *testing.T) {
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "headless")
+	defer func() { MainWindow = main }()
+	MainWindow.SetTargetFPS(30)
+
+	scene := new(headlessTestScene)
+	MainWindow.AddScene("test", scene)
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		if frame == 10 {
+			input.Close()
+		}
+	})
+	MainLoop()
+
+	for _, timedelta := range scene.timedeltas[1:] {
+		if math.Abs(timedelta-1.0/30) > 1e-9 {
+			t.Errorf("expected frames limited to 30 FPS, got a frame time of %v", timedelta)
+		}
+	}
+	stats := MainWindow.FrameStats()
+	if stats.Frames != 11 {
+		t.Errorf("expected 11 frames, got %v", stats.Frames)
+	}
+	if math.Abs(stats.FPS-30) > 1e-6 || math.Abs(stats.FrameTimeP50-1.0/30) > 1e-9 {
+		t.Errorf("expected 30 FPS, got %v", stats)
+	}
+}
+
+func TestFrameStatsPercentiles(t *testing.T) {
+	s := newFrameStats()
+	for i := 1; i <= 2*FrameStatsWindowSize; i++ {
+		s.addFrame(float64(i))
+	}
+	// only the last FrameStatsWindowSize frames count
+	if s.stats.FrameTimeP50 != 180 || s.stats.FrameTimeP99 != 239 {
+		t.Errorf("unexpected percentiles %v %v", s.stats.FrameTimeP50, s.stats.FrameTimeP99)
+	}
+	if s.stats.FPS != 1.0/240 {
+		t.Errorf("expected the FPS of the last frame, got %v", s.stats.FPS)
+	}
+}
@@ -0,0 +1,192 @@
+package windows
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// DefaultGamepadDeadzone is the default deadzone of gamepad axes
+const DefaultGamepadDeadzone = 0.15
+
+// JoystickStateProvider reads the raw state of joysticks.
+// The window uses the one of the platform by default, tests can replace it with a fake.
+type JoystickStateProvider interface {
+	Present(joy glfw.Joystick) bool
+	Name(joy glfw.Joystick) string
+	Axes(joy glfw.Joystick) []float32
+	Buttons(joy glfw.Joystick) []byte
+}
+
+// glfwJoystickProvider reads the joysticks through GLFW
+type glfwJoystickProvider struct{}
+
+func (glfwJoystickProvider) Present(joy glfw.Joystick) bool {
+	return glfw.JoystickPresent(joy)
+}
+
+func (glfwJoystickProvider) Name(joy glfw.Joystick) string {
+	return glfw.GetJoystickName(joy)
+}
+
+func (glfwJoystickProvider) Axes(joy glfw.Joystick) []float32 {
+	return glfw.GetJoystickAxes(joy)
+}
+
+func (glfwJoystickProvider) Buttons(joy glfw.Joystick) []byte {
+	return glfw.GetJoystickButtons(joy)
+}
+
+// GamepadConnectionEvent is sent when a gamepad is connected or disconnected
+type GamepadConnectionEvent struct {
+	Joystick  glfw.Joystick
+	Connected bool
+	Name      string
+}
+
+// GamepadButtonEvent is sent when a gamepad button is pressed or released
+type GamepadButtonEvent struct {
+	Joystick glfw.Joystick
+	Button   int
+	Action   glfw.Action
+}
+
+// GamepadState is the state of a connected gamepad. The deadzone has been applied to the axes.
+type GamepadState struct {
+	Joystick glfw.Joystick
+	Name     string
+	Axes     []float32
+	Buttons  []bool
+}
+
+// GamepadInputHandler can be implemented by scenes that want gamepad events
+type GamepadInputHandler interface {
+	HandleGamepadInput(connectionEvents []GamepadConnectionEvent, buttonEvents []GamepadButtonEvent) WindowAction
+}
+
+// GamepadTicker can be implemented by scenes that want the gamepad state when ticking.
+// TickWithGamepads is then called instead of Tick.
+type GamepadTicker interface {
+	TickWithGamepads(timedelta float64, keyStates []bool, gamepads []GamepadState)
+}
+
+// gamepads polls the joysticks and turns changes into events
+type gamepads struct {
+	provider         JoystickStateProvider
+	deadzone         float32
+	states           []GamepadState
+	connectionEvents []GamepadConnectionEvent
+	buttonEvents     []GamepadButtonEvent
+}
+
+func newGamepads(provider JoystickStateProvider) *gamepads {
+	g := new(gamepads)
+	g.provider = provider
+	g.deadzone = DefaultGamepadDeadzone
+	g.states = make([]GamepadState, 0, 4)
+	return g
+}
+
+func (g *gamepads) find(joy glfw.Joystick) *GamepadState {
+	for i := range g.states {
+		if g.states[i].Joystick == joy {
+			return &g.states[i]
+		}
+	}
+	return nil
+}
+
+// poll reads the joysticks and generates the events of this frame
+func (g *gamepads) poll() {
+	g.connectionEvents = g.connectionEvents[:0]
+	g.buttonEvents = g.buttonEvents[:0]
+
+	provider := g.provider
+	if provider == nil {
+		provider = platform.Joysticks()
+	}
+
+	states := make([]GamepadState, 0, len(g.states))
+	for joy := glfw.Joystick1; joy <= glfw.JoystickLast; joy++ {
+		old := g.find(joy)
+		if !provider.Present(joy) {
+			if old != nil {
+				// release held buttons so nothing gets stuck
+				for button, held := range old.Buttons {
+					if held {
+						g.buttonEvents = append(g.buttonEvents, GamepadButtonEvent{joy, button, glfw.Release})
+					}
+				}
+				g.connectionEvents = append(g.connectionEvents, GamepadConnectionEvent{joy, false, old.Name})
+			}
+			continue
+		}
+
+		state := GamepadState{Joystick: joy}
+		if old == nil {
+			state.Name = provider.Name(joy)
+			g.connectionEvents = append(g.connectionEvents, GamepadConnectionEvent{joy, true, state.Name})
+		} else {
+			state.Name = old.Name
+		}
+
+		rawAxes := provider.Axes(joy)
+		state.Axes = make([]float32, len(rawAxes))
+		for i, value := range rawAxes {
+			state.Axes[i] = applyDeadzone(value, g.deadzone)
+		}
+
+		rawButtons := provider.Buttons(joy)
+		state.Buttons = make([]bool, len(rawButtons))
+		for button, value := range rawButtons {
+			held := value == byte(glfw.Press)
+			state.Buttons[button] = held
+			wasHeld := old != nil && button < len(old.Buttons) && old.Buttons[button]
+			if held && !wasHeld {
+				g.buttonEvents = append(g.buttonEvents, GamepadButtonEvent{joy, button, glfw.Press})
+			} else if !held && wasHeld {
+				g.buttonEvents = append(g.buttonEvents, GamepadButtonEvent{joy, button, glfw.Release})
+			}
+		}
+		states = append(states, state)
+	}
+	g.states = states
+}
+
+// applyDeadzone zeroes values within the deadzone and rescales the rest to still cover -1 to 1
+func applyDeadzone(value, deadzone float32) float32 {
+	if value > -deadzone && value < deadzone {
+		return 0
+	}
+	if deadzone >= 1 {
+		return 0
+	}
+	if value > 0 {
+		return clampAxis((value - deadzone) / (1 - deadzone))
+	}
+	return clampAxis((value + deadzone) / (1 - deadzone))
+}
+
+// SetJoystickStateProvider replaces the source of joystick state, e.g. with a fake in tests.
+// nil uses the joysticks of the platform.
+func (w *Window) SetJoystickStateProvider(provider JoystickStateProvider) {
+	w.gamepads.provider = provider
+}
+
+// SetGamepadDeadzone sets the deadzone (0-1) of gamepad axes
+func (w *Window) SetGamepadDeadzone(deadzone float32) {
+	w.gamepads.deadzone = deadzone
+}
+
+// Gamepads returns the state of the connected gamepads
+func (w *Window) Gamepads() []GamepadState {
+	return w.gamepads.states
+}
+
+// SetGamepadDeadzone sets the deadzone (0-1) of gamepad axes of the main window
+func SetGamepadDeadzone(deadzone float32) {
+	MainWindow.SetGamepadDeadzone(deadzone)
+}
+
+// Gamepads returns the state of the connected gamepads
+func Gamepads() []GamepadState {
+	return MainWindow.Gamepads()
+}
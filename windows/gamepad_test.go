@@ -0,0 +1,153 @@
+package windows
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+type fakeJoystick struct {
+	name    string
+	axes    []float32
+	buttons []byte
+}
+
+type fakeJoystickProvider struct {
+	joysticks map[glfw.Joystick]*fakeJoystick
+}
+
+func newFakeJoystickProvider() *fakeJoystickProvider {
+	return &fakeJoystickProvider{make(map[glfw.Joystick]*fakeJoystick)}
+}
+
+func (p *fakeJoystickProvider) Present(joy glfw.Joystick) bool {
+	_, ok := p.joysticks[joy]
+	return ok
+}
+
+func (p *fakeJoystickProvider) Name(joy glfw.Joystick) string {
+	return p.joysticks[joy].name
+}
+
+func (p *fakeJoystickProvider) Axes(joy glfw.Joystick) []float32 {
+	return p.joysticks[joy].axes
+}
+
+func (p *fakeJoystickProvider) Buttons(joy glfw.Joystick) []byte {
+	return p.joysticks[joy].buttons
+}
+
+type gamepadTestScene struct {
+	SimpleSceneImpl
+	connectionEvents []GamepadConnectionEvent
+	buttonEvents     []GamepadButtonEvent
+	gamepads         []GamepadState
+}
+
+func (s *gamepadTestScene) Init() {
+	s.SetState(StateInited)
+}
+
+func (s *gamepadTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	return WindowActionNone
+}
+
+func (s *gamepadTestScene) HandleGamepadInput(connectionEvents []GamepadConnectionEvent,
+	buttonEvents []GamepadButtonEvent) WindowAction {
+	s.connectionEvents = append(s.connectionEvents, connectionEvents...)
+	s.buttonEvents = append(s.buttonEvents, buttonEvents...)
+	return WindowActionNone
+}
+
+func (s *gamepadTestScene) Tick(timedelta float64, keyStates []bool) {
+	panic("Tick shouldn't be called when TickWithGamepads is implemented")
+}
+
+func (s *gamepadTestScene) TickWithGamepads(timedelta float64, keyStates []bool, gamepads []GamepadState) {
+	s.gamepads = gamepads
+}
+
+func TestGamepadEvents(t *testing.T) {
+	provider := newFakeJoystickProvider()
+	w := NewWindow(800, 600, "test")
+	w.SetJoystickStateProvider(provider)
+	w.SetGamepadDeadzone(0.2)
+	scene := new(gamepadTestScene)
+	w.AddScene("test", scene)
+	w.initScenes()
+
+	frame := func() {
+		w.gamepads.poll()
+		w.dispatchInput()
+		w.tick(0.016)
+	}
+
+	pad := &fakeJoystick{"pad", []float32{0.1, -0.6}, []byte{0, 1}}
+	provider.joysticks[glfw.Joystick2] = pad
+	frame()
+	if len(scene.connectionEvents) != 1 || scene.connectionEvents[0] != (GamepadConnectionEvent{glfw.Joystick2, true, "pad"}) {
+		t.Errorf("expected a connection event, got %v", scene.connectionEvents)
+	}
+	if len(scene.buttonEvents) != 1 || scene.buttonEvents[0] != (GamepadButtonEvent{glfw.Joystick2, 1, glfw.Press}) {
+		t.Errorf("expected a button press, got %v", scene.buttonEvents)
+	}
+	if len(scene.gamepads) != 1 || scene.gamepads[0].Axes[0] != 0 || math.Abs(float64(scene.gamepads[0].Axes[1])+0.5) > 1e-6 {
+		t.Errorf("expected axes with deadzone applied, got %v", scene.gamepads)
+	}
+
+	// unchanged state doesn't send events
+	frame()
+	if len(scene.buttonEvents) != 1 || len(scene.connectionEvents) != 1 {
+		t.Errorf("expected no new events, got %v %v", scene.connectionEvents, scene.buttonEvents)
+	}
+
+	// disconnecting releases held buttons
+	delete(provider.joysticks, glfw.Joystick2)
+	frame()
+	if len(scene.buttonEvents) != 2 || scene.buttonEvents[1] != (GamepadButtonEvent{glfw.Joystick2, 1, glfw.Release}) {
+		t.Errorf("expected a button release, got %v", scene.buttonEvents)
+	}
+	if len(scene.connectionEvents) != 2 || scene.connectionEvents[1].Connected {
+		t.Errorf("expected a disconnection event, got %v", scene.connectionEvents)
+	}
+	if len(scene.gamepads) != 0 {
+		t.Errorf("expected no gamepads, got %v", scene.gamepads)
+	}
+}
+
+func TestGamepadActions(t *testing.T) {
+	provider := newFakeJoystickProvider()
+	w := NewWindow(800, 600, "test")
+	w.SetJoystickStateProvider(provider)
+	w.Actions().Bind("jump", GamepadButtonBinding(AnyJoystick, 0))
+	w.Actions().BindGamepadAxis("horizontal", GamepadAxisBinding{Joystick: glfw.Joystick1, Axis: 0, Invert: true})
+
+	provider.joysticks[glfw.Joystick3] = &fakeJoystick{"other", []float32{1}, []byte{1}}
+	w.gamepads.poll()
+	w.dispatchInput()
+	if !w.Actions().Pressed("jump") {
+		t.Errorf("jump should be pressed by any gamepad")
+	}
+	if w.Actions().Axis("horizontal") != 0 {
+		t.Errorf("axis is bound to another joystick, got %v", w.Actions().Axis("horizontal"))
+	}
+
+	provider.joysticks[glfw.Joystick1] = &fakeJoystick{"first", []float32{1}, []byte{0}}
+	w.gamepads.poll()
+	w.dispatchInput()
+	if w.Actions().Axis("horizontal") != -1 {
+		t.Errorf("expected the inverted axis, got %v", w.Actions().Axis("horizontal"))
+	}
+}
+
+func TestApplyDeadzone(t *testing.T) {
+	cases := []struct{ value, expected float32 }{
+		{0.05, 0}, {-0.1, 0}, {1, 1}, {-1, -1}, {0.55, 0.5},
+	}
+	for _, c := range cases {
+		if result := applyDeadzone(c.value, 0.1); math.Abs(float64(result-c.expected)) > 1e-6 {
+			t.Errorf("applyDeadzone(%v, 0.1) = %v, expected %v", c.value, result, c.expected)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package windows
+
+import (
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/krapulacoders/krapulaengine2/graphics"
+)
+
+// DefaultHeadlessFrameTime is how far the virtual clock of the headless platform advances each frame
+const DefaultHeadlessFrameTime = 1.0 / 60
+
+// InputScript is the scripted input source of a headless window. It is called once per
+// frame, when the events are polled, and sends the input of that frame to the window.
+type InputScript func(frame int, input *HeadlessInput)
+
+// HeadlessPlatform runs windows without a display or a GPU. Time comes from a virtual clock
+// that advances a fixed amount each frame, input comes from scripts and nothing is rendered.
+type HeadlessPlatform struct {
+	// FrameTime is how far the virtual clock advances each frame
+	FrameTime float64
+
+	time      float64
+	frame     int
+	windows   []*headlessWindow
+	scripts   map[*Window]InputScript
+	clipboard string
+}
+
+// NewHeadlessPlatform creates a headless platform. Use it with SetPlatform.
+func NewHeadlessPlatform() *HeadlessPlatform {
+	p := new(HeadlessPlatform)
+	p.FrameTime = DefaultHeadlessFrameTime
+	p.scripts = make(map[*Window]InputScript)
+	return p
+}
+
+// SetInputScript sets the input script of a window
+func (p *HeadlessPlatform) SetInputScript(w *Window, script InputScript) {
+	p.scripts[w] = script
+}
+
+// Frame returns the number of frames polled so far
+func (p *HeadlessPlatform) Frame() int {
+	return p.frame
+}
+
+// Init resets the virtual clock
+func (p *HeadlessPlatform) Init() {
+	p.time = 0
+	p.frame = 0
+}
+
+// Terminate does nothing, the windows are destroyed by the main loop
+func (p *HeadlessPlatform) Terminate() {
+}
+
+// Time returns the time of the virtual clock
+func (p *HeadlessPlatform) Time() float64 {
+	return p.time
+}
+
+// Wait advances the virtual clock
+func (p *HeadlessPlatform) Wait(seconds float64) {
+	p.time += seconds
+}
+
+// PollEvents advances the virtual clock by one frame and runs the input scripts
+func (p *HeadlessPlatform) PollEvents() {
+	p.time += p.FrameTime
+	for _, window := range p.windows {
+		if script, ok := p.scripts[window.window]; ok {
+			script(p.frame, &HeadlessInput{window})
+		}
+	}
+	p.frame++
+}
+
+// Joysticks returns a provider without joysticks
+func (p *HeadlessPlatform) Joysticks() JoystickStateProvider {
+	return headlessJoystickProvider{}
+}
+
+// CreateWindow creates a window with a headless graphics context
+func (p *HeadlessPlatform) CreateWindow(w *Window, share PlatformWindow) PlatformWindow {
+	window := &headlessWindow{
+		platform: p,
+		window:   w,
+		graphics: graphics.NewHeadlessContext(),
+		width:    w.config.Width,
+		height:   w.config.Height,
+	}
+	window.graphics.SetViewPortSize(window.width, window.height)
+	p.windows = append(p.windows, window)
+	return window
+}
+
+// headlessWindow is a window of the headless platform
+type headlessWindow struct {
+	platform      *HeadlessPlatform
+	window        *Window
+	graphics      *graphics.Context
+	width, height int
+	shouldClose   bool
+}
+
+func (w *headlessWindow) Graphics() *graphics.Context {
+	return w.graphics
+}
+
+func (w *headlessWindow) MakeContextCurrent() {
+}
+
+func (w *headlessWindow) ShouldClose() bool {
+	return w.shouldClose
+}
+
+func (w *headlessWindow) SwapBuffers() {
+}
+
+func (w *headlessWindow) GetSize() (int, int) {
+	return w.width, w.height
+}
+
+func (w *headlessWindow) Destroy() {
+	windows := w.platform.windows
+	for i, window := range windows {
+		if window == w {
+			w.platform.windows = append(windows[:i], windows[i+1:]...)
+			break
+		}
+	}
+}
+
+// GetClipboardString returns the clipboard shared by the headless windows
+func (w *headlessWindow) GetClipboardString() (string, error) {
+	return w.platform.clipboard, nil
+}
+
+// SetClipboardString sets the clipboard shared by the headless windows
+func (w *headlessWindow) SetClipboardString(text string) {
+	w.platform.clipboard = text
+}
+
+// ApplyConfig resizes the window. Display modes don't change anything without a display.
+func (w *headlessWindow) ApplyConfig(old, config Config) {
+	w.resize(config.Width, config.Height)
+}
+
+func (w *headlessWindow) resize(width, height int) {
+	if width != w.width || height != w.height {
+		w.width, w.height = width, height
+		w.graphics.SetViewPortSize(width, height)
+		w.window.sendWindowEvent(WindowEvent{Type: WindowResized, Width: width, Height: height})
+	}
+}
+
+// HeadlessInput sends scripted input to a headless window the same way the GLFW callbacks do
+type HeadlessInput struct {
+	window *headlessWindow
+}
+
+// Key sends a key event
+func (in *HeadlessInput) Key(key glfw.Key, action glfw.Action, mods glfw.ModifierKey) {
+	w := in.window.window
+	w.keyQueue = append(w.keyQueue, KeyboardInputEvent{key, 0, action, mods})
+}
+
+// MouseButton sends a mouse button event at the current cursor position
+func (in *HeadlessInput) MouseButton(button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+	w := in.window.window
+	w.mouseQueue = append(w.mouseQueue, MouseInputEvent{button, action, mods, w.mouseX, w.mouseY})
+}
+
+// CursorPos moves the cursor
+func (in *HeadlessInput) CursorPos(x, y float32) {
+	in.window.window.mouseX, in.window.window.mouseY = x, y
+}
+
+// Char sends a text input event
+func (in *HeadlessInput) Char(char rune) {
+	w := in.window.window
+	w.charQueue = append(w.charQueue, CharInputEvent{char})
+}
+
+// Scroll sends a scroll event at the current cursor position
+func (in *HeadlessInput) Scroll(xoff, yoff float64) {
+	w := in.window.window
+	w.scrollQueue = append(w.scrollQueue, ScrollInputEvent{xoff, yoff, w.mouseX, w.mouseY})
+}
+
+// CursorEnter sends a cursor enter or leave event
+func (in *HeadlessInput) CursorEnter(entered bool) {
+	w := in.window.window
+	w.cursorQueue = append(w.cursorQueue, CursorEnterEvent{entered})
+}
+
+// Drop drops files onto the window at the current cursor position
+func (in *HeadlessInput) Drop(paths ...string) {
+	w := in.window.window
+	w.dropQueue = append(w.dropQueue, DropEvent{paths, w.mouseX, w.mouseY})
+}
+
+// Focus gives or takes the focus of the window, like alt-tabbing
+func (in *HeadlessInput) Focus(focused bool) {
+	if focused {
+		in.window.window.sendWindowEvent(WindowEvent{Type: WindowFocused})
+	} else {
+		in.window.window.sendWindowEvent(WindowEvent{Type: WindowUnfocused})
+	}
+}
+
+// Iconify minimizes or restores the window
+func (in *HeadlessInput) Iconify(iconified bool) {
+	if iconified {
+		in.window.window.sendWindowEvent(WindowEvent{Type: WindowIconified})
+	} else {
+		in.window.window.sendWindowEvent(WindowEvent{Type: WindowRestored})
+	}
+}
+
+// Resize resizes the window, like dragging its border
+func (in *HeadlessInput) Resize(width, height int) {
+	in.window.resize(width, height)
+}
+
+// Close asks the window to close, like clicking its close button
+func (in *HeadlessInput) Close() {
+	in.window.shouldClose = true
+}
+
+// headlessJoystickProvider has no joysticks
+type headlessJoystickProvider struct{}
+
+func (headlessJoystickProvider) Present(joy glfw.Joystick) bool {
+	return false
+}
+
+func (headlessJoystickProvider) Name(joy glfw.Joystick) string {
+	return ""
+}
+
+func (headlessJoystickProvider) Axes(joy glfw.Joystick) []float32 {
+	return nil
+}
+
+func (headlessJoystickProvider) Buttons(joy glfw.Joystick) []byte {
+	return nil
+}
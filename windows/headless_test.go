@@ -0,0 +1,105 @@
+package windows
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+type headlessTestScene struct {
+	SimpleSceneImpl
+	keyEvents  []KeyboardInputEvent
+	timedeltas []float64
+}
+
+func (s *headlessTestScene) Init() {
+	s.SetState(StateInited)
+}
+
+func (s *headlessTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	s.keyEvents = append(s.keyEvents, keyEvents...)
+	return WindowActionNone
+}
+
+func (s *headlessTestScene) Tick(timedelta float64, keyStates []bool) {
+	s.timedeltas = append(s.timedeltas, timedelta)
+}
+
+func TestHeadlessMainLoop(t *testing.T) {
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "headless")
+	defer func() { MainWindow = main }()
+
+	scene := new(headlessTestScene)
+	MainWindow.AddScene("test", scene)
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		switch frame {
+		case 2:
+			input.Key(glfw.KeyA, glfw.Press, 0)
+		case 5:
+			input.Close()
+		}
+	})
+	MainLoop()
+
+	if headless.Frame() != 6 {
+		t.Errorf("expected the loop to end on frame 6, got %v", headless.Frame())
+	}
+	if len(scene.keyEvents) != 1 || scene.keyEvents[0].Key != glfw.KeyA {
+		t.Errorf("expected the scripted key press, got %v", scene.keyEvents)
+	}
+	if len(scene.timedeltas) != 5 {
+		t.Fatalf("expected 5 ticks, got %v", scene.timedeltas)
+	}
+	// the first frame starts the clock
+	for _, timedelta := range scene.timedeltas[1:] {
+		if math.Abs(timedelta-DefaultHeadlessFrameTime) > 1e-9 {
+			t.Errorf("expected the virtual frame time, got %v", timedelta)
+		}
+	}
+	if !scene.IsTerminated() || MainWindow.IsOpen() {
+		t.Errorf("the window should be closed when the loop ends")
+	}
+}
+
+func TestHeadlessInputBurst(t *testing.T) {
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "headless")
+	defer func() { MainWindow = main }()
+
+	scene := new(headlessTestScene)
+	MainWindow.AddScene("test", scene)
+	drops := 0
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		switch frame {
+		case 1:
+			// more events in one frame than the input channels used to hold
+			for i := 0; i < 300; i++ {
+				input.Key(glfw.KeyA, glfw.Repeat, 0)
+				input.Char('a')
+				input.Scroll(0, 1)
+				input.Drop("file.png")
+			}
+		case 2:
+			drops = len(MainWindow.dropEvents)
+			input.Close()
+		}
+	})
+	MainLoop()
+
+	if len(scene.keyEvents) != 300 {
+		t.Errorf("expected all scripted key events, got %v", len(scene.keyEvents))
+	}
+	if drops != 300 {
+		t.Errorf("expected all scripted drops, got %v", drops)
+	}
+}
@@ -19,3 +19,176 @@ type MouseInputEvent struct {
 	Mod    glfw.ModifierKey
 	X, Y   float32
 }
+
+// CharInputEvent represents a typed unicode character
+type CharInputEvent struct {
+	Char rune
+}
+
+// ScrollInputEvent represents a glfw scroll event, from a mouse wheel or a touchpad
+type ScrollInputEvent struct {
+	XOffset, YOffset float64
+	X, Y             float32
+}
+
+// CursorEnterEvent is sent when the cursor enters or leaves the window
+type CursorEnterEvent struct {
+	Entered bool
+}
+
+// DropEvent is sent when files are dropped onto the window
+type DropEvent struct {
+	Paths []string
+	X, Y  float32
+}
+
+// InputBatch contains all input a window received during one frame
+type InputBatch struct {
+	KeyEvents    []KeyboardInputEvent
+	MouseEvents  []MouseInputEvent
+	CharEvents   []CharInputEvent
+	ScrollEvents []ScrollInputEvent
+	CursorEvents []CursorEnterEvent
+	DropEvents   []DropEvent
+
+	GamepadConnectionEvents []GamepadConnectionEvent
+	GamepadButtonEvents     []GamepadButtonEvent
+
+	// Cursor position at the end of the frame
+	CursorX, CursorY float32
+
+	// events consumed by the scene handling the batch
+	consumed *consumedInput
+	// indices of the events left in the batch in the events of the window, so that
+	// only unconsumed events update the key states and actions
+	keyIndices, mouseIndices, gamepadButtonIndices []int
+}
+
+// BatchInputHandler can be implemented by scenes that want all kinds of input.
+// HandleInputBatch is then called instead of HandleInput and HandleGamepadInput.
+type BatchInputHandler interface {
+	HandleInputBatch(input *InputBatch) WindowAction
+}
+
+// ConsumeKeyEvent stops KeyEvents[i] from reaching the scenes below
+func (b *InputBatch) ConsumeKeyEvent(i int) {
+	b.consume().keys[i] = true
+}
+
+// ConsumeMouseEvent stops MouseEvents[i] from reaching the scenes below
+func (b *InputBatch) ConsumeMouseEvent(i int) {
+	b.consume().mouse[i] = true
+}
+
+// ConsumeCharEvent stops CharEvents[i] from reaching the scenes below
+func (b *InputBatch) ConsumeCharEvent(i int) {
+	b.consume().chars[i] = true
+}
+
+// ConsumeScrollEvent stops ScrollEvents[i] from reaching the scenes below
+func (b *InputBatch) ConsumeScrollEvent(i int) {
+	b.consume().scrolls[i] = true
+}
+
+// ConsumeDropEvent stops DropEvents[i] from reaching the scenes below
+func (b *InputBatch) ConsumeDropEvent(i int) {
+	b.consume().drops[i] = true
+}
+
+// ConsumeAll stops all events except gamepad connections from reaching the scenes below.
+// Returning WindowActionConsumeInput does the same.
+func (b *InputBatch) ConsumeAll() {
+	b.consume().all = true
+}
+
+// consumedInput keeps track of the events consumed by a scene
+type consumedInput struct {
+	keys, mouse, chars, scrolls, drops map[int]bool
+	all                                bool
+}
+
+func (b *InputBatch) consume() *consumedInput {
+	if b.consumed == nil {
+		b.consumed = &consumedInput{
+			keys:    make(map[int]bool),
+			mouse:   make(map[int]bool),
+			chars:   make(map[int]bool),
+			scrolls: make(map[int]bool),
+			drops:   make(map[int]bool),
+		}
+	}
+	return b.consumed
+}
+
+// removeConsumed removes the consumed events. New slices are created so
+// the events of the window and batches seen earlier are left as they were.
+func (b *InputBatch) removeConsumed() {
+	consumed := b.consumed
+	if consumed == nil {
+		return
+	}
+	b.consumed = nil
+	if consumed.all {
+		b.KeyEvents = nil
+		b.MouseEvents = nil
+		b.CharEvents = nil
+		b.ScrollEvents = nil
+		b.CursorEvents = nil
+		b.DropEvents = nil
+		b.GamepadButtonEvents = nil
+		b.keyIndices = nil
+		b.mouseIndices = nil
+		b.gamepadButtonIndices = nil
+		return
+	}
+
+	keyEvents := make([]KeyboardInputEvent, 0, len(b.KeyEvents))
+	keyIndices := make([]int, 0, len(b.keyIndices))
+	for i, event := range b.KeyEvents {
+		if !consumed.keys[i] {
+			keyEvents = append(keyEvents, event)
+			if i < len(b.keyIndices) {
+				keyIndices = append(keyIndices, b.keyIndices[i])
+			}
+		}
+	}
+	b.KeyEvents = keyEvents
+	b.keyIndices = keyIndices
+
+	mouseEvents := make([]MouseInputEvent, 0, len(b.MouseEvents))
+	mouseIndices := make([]int, 0, len(b.mouseIndices))
+	for i, event := range b.MouseEvents {
+		if !consumed.mouse[i] {
+			mouseEvents = append(mouseEvents, event)
+			if i < len(b.mouseIndices) {
+				mouseIndices = append(mouseIndices, b.mouseIndices[i])
+			}
+		}
+	}
+	b.MouseEvents = mouseEvents
+	b.mouseIndices = mouseIndices
+
+	charEvents := make([]CharInputEvent, 0, len(b.CharEvents))
+	for i, event := range b.CharEvents {
+		if !consumed.chars[i] {
+			charEvents = append(charEvents, event)
+		}
+	}
+	b.CharEvents = charEvents
+
+	scrollEvents := make([]ScrollInputEvent, 0, len(b.ScrollEvents))
+	for i, event := range b.ScrollEvents {
+		if !consumed.scrolls[i] {
+			scrollEvents = append(scrollEvents, event)
+		}
+	}
+	b.ScrollEvents = scrollEvents
+
+	dropEvents := make([]DropEvent, 0, len(b.DropEvents))
+	for i, event := range b.DropEvents {
+		if !consumed.drops[i] {
+			dropEvents = append(dropEvents, event)
+		}
+	}
+	b.DropEvents = dropEvents
+}
@@ -0,0 +1,131 @@
+package windows
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+type batchTestScene struct {
+	SimpleSceneImpl
+	batches     []InputBatch
+	transparent bool
+}
+
+func (s *batchTestScene) IsTransparent() bool {
+	return s.transparent
+}
+
+func (s *batchTestScene) Init() {
+	s.SetState(StateInited)
+}
+
+func (s *batchTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	panic("HandleInput shouldn't be called when HandleInputBatch is implemented")
+}
+
+func (s *batchTestScene) HandleInputBatch(input *InputBatch) WindowAction {
+	s.batches = append(s.batches, *input)
+	return WindowActionNone
+}
+
+func (s *batchTestScene) Tick(timedelta float64, keyStates []bool) {}
+
+func TestInputBatch(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	batchScene := new(batchTestScene)
+	plainScene := newStackTestScene(false)
+	w.AddScene("plain", plainScene)
+	w.AddScene("batch", batchScene)
+	w.PushScene("batch")
+	batchScene.transparent = true
+	w.initScenes()
+
+	w.mouseX, w.mouseY = 10, 20
+	w.charQueue = append(w.charQueue, CharInputEvent{'ä'})
+	w.scrollQueue = append(w.scrollQueue, ScrollInputEvent{0, -1.5, 10, 20})
+	w.cursorQueue = append(w.cursorQueue, CursorEnterEvent{false})
+	w.drainInput()
+	w.dispatchInput()
+
+	if len(batchScene.batches) != 1 {
+		t.Fatalf("expected one batch, got %v", len(batchScene.batches))
+	}
+	batch := batchScene.batches[0]
+	if len(batch.CharEvents) != 1 || batch.CharEvents[0].Char != 'ä' {
+		t.Errorf("expected a char event, got %v", batch.CharEvents)
+	}
+	if len(batch.ScrollEvents) != 1 || batch.ScrollEvents[0].YOffset != -1.5 {
+		t.Errorf("expected a scroll event, got %v", batch.ScrollEvents)
+	}
+	if len(batch.CursorEvents) != 1 || batch.CursorEvents[0].Entered {
+		t.Errorf("expected a cursor leave event, got %v", batch.CursorEvents)
+	}
+	if batch.CursorX != 10 || batch.CursorY != 20 {
+		t.Errorf("expected the cursor position, got %v, %v", batch.CursorX, batch.CursorY)
+	}
+	if plainScene.inputs != 1 {
+		t.Errorf("the scene below should still get input through HandleInput")
+	}
+}
+
+func TestDropAndClipboard(t *testing.T) {
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "headless")
+	defer func() { MainWindow = main }()
+
+	scene := new(batchTestScene)
+	MainWindow.AddScene("test", scene)
+	var pasted string
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		switch frame {
+		case 1:
+			input.CursorPos(5, 6)
+			input.Drop("level.json", "tiles.png")
+			SetClipboard("copied")
+		case 2:
+			pasted, _ = GetClipboard()
+			input.Close()
+		}
+	})
+	MainLoop()
+
+	if pasted != "copied" {
+		t.Errorf("expected the text on the clipboard, got %q", pasted)
+	}
+	drops := scene.batches[1].DropEvents
+	if len(drops) != 1 || len(drops[0].Paths) != 2 || drops[0].Paths[1] != "tiles.png" || drops[0].X != 5 {
+		t.Errorf("expected the dropped files, got %v", drops)
+	}
+	if _, err := GetClipboard(); err == nil {
+		t.Errorf("a closed window has no clipboard")
+	}
+}
+
+func TestInputCallbacksDontBlock(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	keyCallback := w.keyEventHandler()
+	charCallback := w.charEventHandler()
+	scrollCallback := w.scrollEventHandler()
+	dropCallback := w.dropHandler()
+	// more events in one frame than the old channels could hold
+	for i := 0; i < 500; i++ {
+		keyCallback(nil, glfw.KeyA, 0, glfw.Repeat, 0)
+		charCallback(nil, 'a')
+		scrollCallback(nil, 0, 1)
+		dropCallback(nil, []string{"file.png"})
+	}
+	w.drainInput()
+	if len(w.keyEvents) != 500 || len(w.charEvents) != 500 || len(w.scrollEvents) != 500 || len(w.dropEvents) != 500 {
+		t.Errorf("expected all events, got %v keys, %v chars, %v scrolls and %v drops",
+			len(w.keyEvents), len(w.charEvents), len(w.scrollEvents), len(w.dropEvents))
+	}
+	w.drainInput()
+	if len(w.keyEvents) != 0 || len(w.charEvents) != 0 || len(w.scrollEvents) != 0 || len(w.dropEvents) != 0 {
+		t.Errorf("the events should only be drained once")
+	}
+}
@@ -0,0 +1,261 @@
+package windows
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultLoadBudget is how long the main loop spends on queued loading tasks each frame
+const DefaultLoadBudget = 4 * time.Millisecond
+
+// number of queued loading tasks run each frame in deterministic mode
+const deterministicLoadTasks = 4
+
+// AsyncScene can be implemented by scenes that are too slow to init during a frame.
+// Load is called on its own goroutine and loads CPU-side data. GL resources must be created
+// on the main thread, by queuing tasks with Loader.RunOnMainThread. Once Load has returned and
+// the tasks have run, Init is called on the main thread and should only finish up.
+//
+// An async scene that becomes active without LoadScene, e.g. by PushScene, is loaded right away.
+type AsyncScene interface {
+	Load(loader *Loader) error
+}
+
+// ProgressScene can be implemented by loading screens to be told the progress (0-1) of the scene being loaded
+type ProgressScene interface {
+	SetLoadProgress(progress float32)
+}
+
+// Loader is passed to AsyncScene.Load
+type Loader struct {
+	tasks chan func()
+	// run tasks right away instead of queuing them
+	direct bool
+
+	pending  int32
+	finished int32
+	// closed when Load has returned
+	loaded chan struct{}
+
+	mutex    sync.Mutex
+	progress float32
+	err      error
+}
+
+// RunOnMainThread queues a task, e.g. a GL upload, to run on the main thread with
+// the window's context current. The tasks run in order, a few each frame.
+func (l *Loader) RunOnMainThread(task func()) {
+	if l.direct {
+		task()
+		return
+	}
+	atomic.AddInt32(&l.pending, 1)
+	l.tasks <- func() {
+		task()
+		atomic.AddInt32(&l.pending, -1)
+	}
+}
+
+// SetProgress tells the loading screen how far (0-1) loading has come
+func (l *Loader) SetProgress(progress float32) {
+	l.mutex.Lock()
+	l.progress = progress
+	l.mutex.Unlock()
+}
+
+// Progress returns how far (0-1) loading has come
+func (l *Loader) Progress() float32 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.progress
+}
+
+// Done returns true once Load has returned and all queued tasks have run
+func (l *Loader) Done() bool {
+	return atomic.LoadInt32(&l.finished) == 1 && atomic.LoadInt32(&l.pending) == 0
+}
+
+// Err returns the error Load returned
+func (l *Loader) Err() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.err
+}
+
+func (l *Loader) load(scene AsyncScene) {
+	err := scene.Load(l)
+	l.mutex.Lock()
+	l.err = err
+	l.mutex.Unlock()
+	atomic.StoreInt32(&l.finished, 1)
+	close(l.loaded)
+}
+
+// nextTask waits for the next queued task. Returns nil once Load has returned and all its tasks have been taken.
+func (l *Loader) nextTask() func() {
+	select {
+	case task := <-l.tasks:
+		return task
+	case <-l.loaded:
+		select {
+		case task := <-l.tasks:
+			return task
+		default:
+			return nil
+		}
+	}
+}
+
+// sceneLoad is a scene being loaded in the background
+type sceneLoad struct {
+	id     string
+	scene  Scene
+	loader *Loader
+	// the loading screen and its position in the scene stack
+	screen   string
+	position int
+	// in deterministic mode, the task to run first on the next frame
+	next func()
+}
+
+// LoadScene shows the loading screen scene on top of the stack, replacing the current scene,
+// and loads the async scene id in the background. When loading is done the scene replaces the
+// loading screen, even if other scenes have been pushed on top of it. If the loading screen has
+// been removed from the stack, the scene is only inited. If loading fails the loading screen stays
+// and LoadError returns the error.
+func (w *Window) LoadScene(id string, loadingScreen string) {
+	scene := w.getScene(id)
+	async, ok := scene.(AsyncScene)
+	if !ok {
+		panic("Scene doesn't implement AsyncScene: " + id)
+	}
+	if w.loading != nil {
+		panic("Already loading scene " + w.loading.id)
+	}
+	w.loadError = nil
+	w.ReplaceScene(loadingScreen)
+	if scene.IsInited() {
+		w.ReplaceScene(id)
+		return
+	}
+
+	loader := &Loader{tasks: w.loadTasks, loaded: make(chan struct{})}
+	w.loading = &sceneLoad{id: id, scene: scene, loader: loader, screen: loadingScreen, position: len(w.sceneStack) - 1}
+	go loader.load(async)
+}
+
+// IsLoading returns true while a scene is loaded in the background
+func (w *Window) IsLoading() bool {
+	return w.loading != nil
+}
+
+// LoadError returns the error of the last failed LoadScene, or nil
+func (w *Window) LoadError() error {
+	return w.loadError
+}
+
+// SetLoadBudget sets how long the main loop spends on queued loading tasks each frame.
+// At least one task runs each frame. In deterministic mode the budget isn't used, a fixed
+// number of tasks runs each frame instead.
+func (w *Window) SetLoadBudget(budget time.Duration) {
+	w.loadBudget = budget
+}
+
+// initScene inits a scene, loading it first if it's an async scene.
+// If loading fails the scene isn't inited and LoadError returns the error.
+func (w *Window) initScene(scene Scene) error {
+	if async, ok := scene.(AsyncScene); ok {
+		loader := &Loader{direct: true, loaded: make(chan struct{})}
+		loader.load(async)
+		if err := loader.Err(); err != nil {
+			fmt.Println("Can't load scene:", err)
+			w.loadError = err
+			return err
+		}
+	}
+	scene.Init()
+	return nil
+}
+
+// updateLoading runs queued loading tasks within the budget and
+// replaces the loading screen once the scene has loaded
+func (w *Window) updateLoading() {
+	load := w.loading
+	if load != nil && IsDeterministic() {
+		load.runDeterministicTasks(deterministicLoadTasks)
+	} else {
+		w.runLoadTasks()
+	}
+	if load == nil {
+		return
+	}
+	if progress, ok := w.scenes[load.screen].(ProgressScene); ok {
+		progress.SetLoadProgress(load.loader.Progress())
+	}
+	if !load.loader.Done() {
+		return
+	}
+	w.loading = nil
+	if err := load.loader.Err(); err != nil {
+		fmt.Println("Can't load scene "+load.id+":", err)
+		w.loadError = err
+		return
+	}
+	load.scene.Init()
+	w.replaceLoadingScreen(load)
+}
+
+// replaceLoadingScreen puts the loaded scene in the place of the loading screen in the stack.
+// Nothing is done if the loading screen is no longer there or the scene is already on the stack.
+func (w *Window) replaceLoadingScreen(load *sceneLoad) {
+	if load.position >= len(w.sceneStack) || w.sceneStack[load.position] != load.screen {
+		return
+	}
+	for _, stacked := range w.sceneStack {
+		if stacked == load.id {
+			return
+		}
+	}
+	previouslyActive := w.activeScenes()
+	w.sceneStack[load.position] = load.id
+	w.updateSceneStates(previouslyActive)
+}
+
+// runLoadTasks runs the queued loading tasks until the budget is used
+func (w *Window) runLoadTasks() {
+	deadline := time.Now().Add(w.loadBudget)
+	for done := false; !done; {
+		select {
+		case task := <-w.loadTasks:
+			task()
+			done = time.Now().After(deadline)
+		default:
+			done = true
+		}
+	}
+}
+
+// runDeterministicTasks runs n tasks, waiting for the loader to queue them, and then waits until
+// the loader has queued another task or returned. That way loading takes the same number of
+// frames every run, however fast the loader goroutine is.
+func (load *sceneLoad) runDeterministicTasks(n int) {
+	for i := 0; i < n; i++ {
+		task := load.next
+		load.next = nil
+		if task == nil {
+			task = load.loader.nextTask()
+		}
+		if task == nil {
+			return
+		}
+		task()
+	}
+	load.next = load.loader.nextTask()
+}
+
+// LoadScene loads an async scene of the main window in the background while showing the loading screen
+func LoadScene(id string, loadingScreen string) {
+	MainWindow.LoadScene(id, loadingScreen)
+}
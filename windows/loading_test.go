@@ -0,0 +1,187 @@
+package windows
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type asyncTestScene struct {
+	stackTestScene
+	tasks     int
+	uploaded  int
+	initedAt  int
+	loadError error
+}
+
+func (s *asyncTestScene) Load(loader *Loader) error {
+	for i := 0; i < s.tasks; i++ {
+		loader.RunOnMainThread(func() {
+			s.uploaded++
+		})
+		loader.SetProgress(float32(i+1) / float32(s.tasks))
+	}
+	return s.loadError
+}
+
+func (s *asyncTestScene) Init() {
+	s.initedAt = s.uploaded
+	s.SetState(StateInited)
+}
+
+type loadingScreenTestScene struct {
+	stackTestScene
+	progress []float32
+}
+
+func (s *loadingScreenTestScene) SetLoadProgress(progress float32) {
+	s.progress = append(s.progress, progress)
+}
+
+func TestLoadScene(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	menu := newStackTestScene(false)
+	loadingScreen := new(loadingScreenTestScene)
+	level := &asyncTestScene{tasks: 3}
+	w.AddScene("menu", menu)
+	w.AddScene("loading", loadingScreen)
+	w.AddScene("level", level)
+	w.initScenes()
+	if level.IsInited() {
+		t.Fatalf("async scenes shouldn't be inited with the others")
+	}
+
+	// one task per frame
+	w.SetLoadBudget(0)
+	w.LoadScene("level", "loading")
+	if w.CurrentScene() != loadingScreen || menu.IsRunning() {
+		t.Errorf("the loading screen should replace the menu")
+	}
+	for frames := 0; w.IsLoading(); frames++ {
+		if frames > 1000 {
+			t.Fatalf("loading doesn't finish")
+		}
+		w.updateLoading()
+		time.Sleep(time.Millisecond)
+	}
+
+	if w.CurrentScene() != level || !level.IsRunning() {
+		t.Errorf("the loaded scene should replace the loading screen")
+	}
+	if level.uploaded != 3 || level.initedAt != 3 {
+		t.Errorf("all tasks should run before Init, got %v of %v", level.initedAt, level.uploaded)
+	}
+	if len(loadingScreen.progress) == 0 || loadingScreen.progress[len(loadingScreen.progress)-1] != 1 {
+		t.Errorf("the loading screen should see the progress, got %v", loadingScreen.progress)
+	}
+}
+
+func TestLoadSceneError(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	loadingScreen := new(loadingScreenTestScene)
+	level := &asyncTestScene{loadError: errors.New("missing level file")}
+	w.AddScene("loading", loadingScreen)
+	w.AddScene("level", level)
+	w.initScenes()
+
+	w.LoadScene("level", "loading")
+	for w.IsLoading() {
+		w.updateLoading()
+		time.Sleep(time.Millisecond)
+	}
+	if w.LoadError() == nil || w.CurrentScene() != loadingScreen || level.IsInited() {
+		t.Errorf("a failed load should leave the loading screen, got error %v", w.LoadError())
+	}
+}
+
+func TestPushAsyncSceneLoadsRightAway(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	level := &asyncTestScene{tasks: 2}
+	w.AddScene("menu", newStackTestScene(false))
+	w.AddScene("level", level)
+	w.initScenes()
+
+	w.PushScene("level")
+	if !level.IsRunning() || level.initedAt != 2 {
+		t.Errorf("pushing an async scene should load and run it, uploaded %v", level.initedAt)
+	}
+}
+
+func TestLoadSceneReplacesOnlyTheLoadingScreen(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	loadingScreen := new(loadingScreenTestScene)
+	level := &asyncTestScene{tasks: 1}
+	dialog := newStackTestScene(false)
+	w.AddScene("loading", loadingScreen)
+	w.AddScene("level", level)
+	w.AddScene("dialog", dialog)
+	w.initScenes()
+
+	load := func() {
+		for w.IsLoading() {
+			w.updateLoading()
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// a scene pushed on top of the loading screen stays on top
+	w.LoadScene("level", "loading")
+	w.PushScene("dialog")
+	load()
+	if len(w.sceneStack) != 2 || w.sceneStack[0] != "level" || w.CurrentScene() != dialog {
+		t.Errorf("the loaded scene should take the place of the loading screen, got %v", w.sceneStack)
+	}
+	if level.IsRunning() || !dialog.IsRunning() {
+		t.Errorf("the loaded scene should be covered by the dialog")
+	}
+
+	// a loading screen that has been removed isn't swapped
+	w.PopScene()
+	w.PopScene()
+	other := &asyncTestScene{tasks: 1}
+	w.AddScene("other", other)
+	w.LoadScene("other", "loading")
+	w.ReplaceScene("dialog")
+	load()
+	if len(w.sceneStack) != 1 || w.CurrentScene() != dialog || !other.IsInited() || other.IsRunning() {
+		t.Errorf("the loaded scene should only be inited, got %v", w.sceneStack)
+	}
+}
+
+func TestPushAsyncSceneLoadError(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	level := &asyncTestScene{loadError: errors.New("missing level file")}
+	w.AddScene("menu", newStackTestScene(false))
+	w.AddScene("level", level)
+	w.initScenes()
+
+	w.PushScene("level")
+	if w.LoadError() == nil || level.IsInited() || level.IsRunning() {
+		t.Errorf("a failed load should be reported by LoadError, got %v", w.LoadError())
+	}
+}
+
+func TestLoadSceneDeterministic(t *testing.T) {
+	SetDeterministic(DeterministicConfig{FrameTime: 0.01})
+	defer ClearDeterministic()
+
+	for run := 0; run < 20; run++ {
+		w := NewWindow(800, 600, "test")
+		level := &asyncTestScene{tasks: 2 * deterministicLoadTasks}
+		w.AddScene("loading", new(loadingScreenTestScene))
+		w.AddScene("level", level)
+		w.initScenes()
+
+		w.LoadScene("level", "loading")
+		frames := 0
+		for ; w.IsLoading(); frames++ {
+			if frames > 100 {
+				t.Fatalf("loading doesn't finish")
+			}
+			w.updateLoading()
+		}
+		if frames != 2 || !level.IsRunning() || level.initedAt != level.tasks {
+			t.Fatalf("run %v: expected loading to take 2 frames, took %v", run, frames)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package windows
+
+import (
+	"sort"
+)
+
+// AddOverlay adds a visible overlay with z-order 0
+func AddOverlay(id string, scene Scene) {
+	MainWindow.AddOverlay(id, scene)
+}
+
+// AddOverlay adds a visible overlay with z-order 0
+func (w *Window) AddOverlay(id string, scene Scene) {
+	w.AddOverlayAt(id, scene, 0)
+}
+
+// AddOverlayAt adds a visible overlay with the given z-order. Overlays with a higher z are on top,
+// and of overlays with the same z the one added last is on top.
+func (w *Window) AddOverlayAt(id string, scene Scene, z int) {
+	if _, exists := w.overlays[id]; exists {
+		panic("Tried adding overlay twice to window")
+	}
+	w.overlays[id] = scene
+	w.overlayZ[id] = z
+	w.activeOverlays[id] = true
+	w.overlayOrder = append(w.overlayOrder, id)
+	w.sortOverlays()
+	w.observeScene(id, scene)
+	if w.running {
+		if !scene.IsInited() {
+			scene.Init()
+		}
+		scene.SetFocused(w.focused)
+		logSceneError(runScene(scene))
+	}
+}
+
+// SetOverlayZ changes the z-order of an overlay
+func (w *Window) SetOverlayZ(id string, z int) {
+	w.getOverlay(id)
+	w.overlayZ[id] = z
+	w.sortOverlays()
+}
+
+// ShowOverlay makes an overlay visible, so it gets input and is ticked
+func (w *Window) ShowOverlay(id string) {
+	overlay := w.getOverlay(id)
+	if w.activeOverlays[id] {
+		return
+	}
+	w.activeOverlays[id] = true
+	if w.running && !overlay.IsRunning() {
+		logSceneError(runScene(overlay))
+	}
+}
+
+// HideOverlay hides an overlay. It is paused until it is shown again.
+func (w *Window) HideOverlay(id string) {
+	overlay := w.getOverlay(id)
+	if !w.activeOverlays[id] {
+		return
+	}
+	w.activeOverlays[id] = false
+	if w.running && overlay.IsRunning() {
+		logSceneError(overlay.Pause())
+	}
+}
+
+// ToggleOverlay shows a hidden overlay or hides a visible one
+func (w *Window) ToggleOverlay(id string) {
+	if w.IsOverlayVisible(id) {
+		w.HideOverlay(id)
+	} else {
+		w.ShowOverlay(id)
+	}
+}
+
+// IsOverlayVisible returns true if the overlay is shown
+func (w *Window) IsOverlayVisible(id string) bool {
+	w.getOverlay(id)
+	return w.activeOverlays[id]
+}
+
+func (w *Window) getOverlay(id string) Scene {
+	overlay, exists := w.overlays[id]
+	if !exists {
+		panic("No overlay with id " + id)
+	}
+	return overlay
+}
+
+// sortOverlays orders the overlays bottom to top
+func (w *Window) sortOverlays() {
+	sort.SliceStable(w.overlayOrder, func(i, j int) bool {
+		return w.overlayZ[w.overlayOrder[i]] < w.overlayZ[w.overlayOrder[j]]
+	})
+}
+
+// visibleOverlays returns the visible overlays, top first
+func (w *Window) visibleOverlays() []Scene {
+	overlays := make([]Scene, 0, len(w.overlayOrder))
+	for i := len(w.overlayOrder) - 1; i >= 0; i-- {
+		id := w.overlayOrder[i]
+		if w.activeOverlays[id] {
+			overlays = append(overlays, w.overlays[id])
+		}
+	}
+	return overlays
+}
+
+// ShowOverlay makes an overlay of the main window visible
+func ShowOverlay(id string) {
+	MainWindow.ShowOverlay(id)
+}
+
+// HideOverlay hides an overlay of the main window
+func HideOverlay(id string) {
+	MainWindow.HideOverlay(id)
+}
+
+// ToggleOverlay shows or hides an overlay of the main window
+func ToggleOverlay(id string) {
+	MainWindow.ToggleOverlay(id)
+}
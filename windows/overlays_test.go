@@ -0,0 +1,94 @@
+package windows
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+// consumingTestScene records its input and consumes the events matching its filters
+type consumingTestScene struct {
+	batchTestScene
+	consumeKey   glfw.Key
+	consumeAll   bool
+	handledOrder *[]string
+	name         string
+}
+
+func (s *consumingTestScene) HandleInputBatch(input *InputBatch) WindowAction {
+	s.batchTestScene.HandleInputBatch(input)
+	*s.handledOrder = append(*s.handledOrder, s.name)
+	for i, event := range input.KeyEvents {
+		if event.Key == s.consumeKey {
+			input.ConsumeKeyEvent(i)
+		}
+	}
+	if s.consumeAll {
+		return WindowActionConsumeInput
+	}
+	return WindowActionNone
+}
+
+func TestOverlayOrderAndConsumption(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	var order []string
+	scene := &consumingTestScene{name: "scene", handledOrder: &order}
+	low := &consumingTestScene{name: "low", handledOrder: &order, consumeKey: glfw.KeyA}
+	high := &consumingTestScene{name: "high", handledOrder: &order, consumeKey: glfw.KeyB}
+	w.AddScene("scene", scene)
+	w.AddOverlayAt("high", high, 10)
+	w.AddOverlayAt("low", low, 1)
+	w.Actions().Bind("a", KeyBinding(glfw.KeyA, 0))
+	w.Actions().Bind("c", KeyBinding(glfw.KeyC, 0))
+	w.initScenes()
+
+	frame := func(keys ...glfw.Key) {
+		for _, key := range keys {
+			w.keyQueue = append(w.keyQueue, keyEvent(key, glfw.Press))
+		}
+		w.drainInput()
+		w.dispatchInput()
+	}
+
+	frame(glfw.KeyA, glfw.KeyB, glfw.KeyC)
+	if len(order) != 3 || order[0] != "high" || order[1] != "low" || order[2] != "scene" {
+		t.Errorf("expected input top-down, got %v", order)
+	}
+	if len(low.batches[0].KeyEvents) != 2 {
+		t.Errorf("the low overlay shouldn't see the key consumed above it, got %v", low.batches[0].KeyEvents)
+	}
+	if len(scene.batches[0].KeyEvents) != 1 || scene.batches[0].KeyEvents[0].Key != glfw.KeyC {
+		t.Errorf("the scene should only see the unconsumed key, got %v", scene.batches[0].KeyEvents)
+	}
+	if w.keyStates[glfw.KeyA] || w.keyStates[glfw.KeyB] || !w.keyStates[glfw.KeyC] {
+		t.Errorf("only the unconsumed key should update the key states")
+	}
+	if w.Actions().Pressed("a") || !w.Actions().Pressed("c") {
+		t.Errorf("only the unconsumed key should update the actions")
+	}
+
+	// a hidden overlay gets no input and is paused
+	w.HideOverlay("high")
+	if high.IsRunning() || w.IsOverlayVisible("high") {
+		t.Errorf("a hidden overlay should be paused")
+	}
+	low.consumeAll = true
+	frame(glfw.KeyB)
+	if len(high.batches) != 1 {
+		t.Errorf("a hidden overlay shouldn't get input")
+	}
+	if len(scene.batches[1].KeyEvents) != 0 {
+		t.Errorf("all input should be consumed, got %v", scene.batches[1].KeyEvents)
+	}
+	w.keyQueue = append(w.keyQueue, keyEvent(glfw.KeyC, glfw.Release))
+	w.drainInput()
+	w.dispatchInput()
+	if w.keyStates[glfw.KeyC] {
+		t.Errorf("a consumed release should still release the key")
+	}
+
+	w.ToggleOverlay("high")
+	if !high.IsRunning() || !w.IsOverlayVisible("high") {
+		t.Errorf("toggling should show the overlay again")
+	}
+}
@@ -0,0 +1,254 @@
+package windows
+
+import (
+	"time"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/krapulacoders/krapulaengine2/graphics"
+)
+
+// Platform creates the OS windows and runs the event loop. GLFW is used by default,
+// HeadlessPlatform runs the windows without a display.
+type Platform interface {
+	// Init is called before the first window is opened
+	Init()
+	// Terminate is called when the main loop ends
+	Terminate()
+	// Time returns the time in seconds
+	Time() float64
+	// Wait sleeps for the given number of seconds
+	Wait(seconds float64)
+	// PollEvents sends the input of all windows to their input channels
+	PollEvents()
+	// CreateWindow creates the platform window of w. share is the window whose
+	// textures and shaders are shared, or nil.
+	CreateWindow(w *Window, share PlatformWindow) PlatformWindow
+	// Joysticks returns the source of joystick state
+	Joysticks() JoystickStateProvider
+}
+
+// PlatformWindow is a window created by a Platform
+type PlatformWindow interface {
+	// Graphics returns the graphics context of the window
+	Graphics() *graphics.Context
+	MakeContextCurrent()
+	ShouldClose() bool
+	SwapBuffers()
+	GetSize() (int, int)
+	Destroy()
+	// ApplyConfig changes the window from the old config to the new one
+	ApplyConfig(old, config Config)
+	GetClipboardString() (string, error)
+	SetClipboardString(text string)
+}
+
+// the platform used to open windows
+var platform Platform = glfwPlatform{}
+
+// set to true once the platform has been inited
+var platformInited bool
+
+// SetPlatform sets the platform used to open windows. It must be called before any window is opened.
+func SetPlatform(p Platform) {
+	if len(openWindows) > 0 {
+		panic("Cannot change the platform while windows are open")
+	}
+	platform = p
+}
+
+// GetPlatform returns the platform used to open windows
+func GetPlatform() Platform {
+	return platform
+}
+
+func initPlatform() {
+	if platformInited {
+		return
+	}
+	platformInited = true
+	platform.Init()
+}
+
+func terminatePlatform() {
+	platform.Terminate()
+	platformInited = false
+}
+
+// glfwPlatform opens real windows using GLFW
+type glfwPlatform struct{}
+
+// glfwWindow is a GLFW window with its graphics context
+type glfwWindow struct {
+	*glfw.Window
+	graphics *graphics.Context
+	// where to put the window when returning to windowed mode
+	windowedX, windowedY int
+	// GLFW 3.2 has no content scale callback, so it is derived from the framebuffer and window sizes
+	scaleX, scaleY float32
+}
+
+func (glfwPlatform) Init() {
+	if err := glfw.Init(); err != nil {
+		panic("Can't init glfw: " + err.Error())
+	}
+
+	glfw.WindowHint(glfw.ContextVersionMajor, 3)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+}
+
+func (glfwPlatform) Terminate() {
+	glfw.Terminate()
+}
+
+func (glfwPlatform) Time() float64 {
+	return glfw.GetTime()
+}
+
+func (glfwPlatform) Wait(seconds float64) {
+	time.Sleep(time.Duration(seconds * float64(time.Second)))
+}
+
+func (glfwPlatform) PollEvents() {
+	glfw.PollEvents()
+}
+
+func (glfwPlatform) Joysticks() JoystickStateProvider {
+	return glfwJoystickProvider{}
+}
+
+func (glfwPlatform) CreateWindow(w *Window, share PlatformWindow) PlatformWindow {
+	var shareWindow *glfw.Window
+	if share != nil {
+		shareWindow = share.(*glfwWindow).Window
+	}
+	config := w.config
+	glfw.WindowHint(glfw.Resizable, glfwBool(config.Resizable))
+	glfw.WindowHint(glfw.Samples, config.Samples)
+	window, err := glfw.CreateWindow(config.Width, config.Height, config.Title, nil, shareWindow)
+	if err != nil {
+		panic("Can't create window")
+	}
+
+	window.MakeContextCurrent()
+
+	result := &glfwWindow{Window: window, graphics: graphics.NewContext(), scaleX: 1, scaleY: 1}
+	window.SetKeyCallback(w.keyEventHandler())
+	window.SetCursorPosCallback(w.cursorPosHandler())
+	window.SetMouseButtonCallback(w.mouseButtonEventHandler())
+	window.SetCharCallback(w.charEventHandler())
+	window.SetScrollCallback(w.scrollEventHandler())
+	window.SetCursorEnterCallback(w.cursorEnterHandler())
+	window.SetDropCallback(w.dropHandler())
+	window.SetFramebufferSizeCallback(func(window *glfw.Window, width, height int) {
+		result.graphics.SetViewPortSize(width, height)
+		result.updateContentScale(w)
+	})
+	window.SetSizeCallback(func(window *glfw.Window, width, height int) {
+		w.sendWindowEvent(WindowEvent{Type: WindowResized, Width: width, Height: height})
+		result.updateContentScale(w)
+	})
+	window.SetFocusCallback(func(window *glfw.Window, focused bool) {
+		if focused {
+			w.sendWindowEvent(WindowEvent{Type: WindowFocused})
+		} else {
+			w.sendWindowEvent(WindowEvent{Type: WindowUnfocused})
+		}
+	})
+	window.SetIconifyCallback(func(window *glfw.Window, iconified bool) {
+		if iconified {
+			w.sendWindowEvent(WindowEvent{Type: WindowIconified})
+		} else {
+			w.sendWindowEvent(WindowEvent{Type: WindowRestored})
+		}
+	})
+	// also set initial viewpost size...
+	width, height := window.GetFramebufferSize()
+	result.graphics.SetViewPortSize(width, height)
+	result.updateContentScale(w)
+
+	// start windowed and switch to the configured mode
+	old := config
+	old.DisplayMode = DisplayWindowed
+	result.ApplyConfig(old, config)
+	return result
+}
+
+// updateContentScale sends a content scale event when the ratio of framebuffer to window size changes
+func (w *glfwWindow) updateContentScale(window *Window) {
+	width, height := w.GetSize()
+	fbWidth, fbHeight := w.GetFramebufferSize()
+	if width == 0 || height == 0 {
+		// iconified
+		return
+	}
+	scaleX, scaleY := float32(fbWidth)/float32(width), float32(fbHeight)/float32(height)
+	if scaleX != w.scaleX || scaleY != w.scaleY {
+		w.scaleX, w.scaleY = scaleX, scaleY
+		window.sendWindowEvent(WindowEvent{Type: WindowContentScaleChanged, ScaleX: scaleX, ScaleY: scaleY})
+	}
+}
+
+func glfwBool(value bool) int {
+	if value {
+		return glfw.True
+	}
+	return glfw.False
+}
+
+// glfwSizeLimit turns a size limit of the config into a GLFW one
+func glfwSizeLimit(limit int) int {
+	if limit <= 0 {
+		return glfw.DontCare
+	}
+	return limit
+}
+
+// glfwMonitor returns the monitor with the given index, or the primary monitor
+func glfwMonitor(index int) *glfw.Monitor {
+	monitors := glfw.GetMonitors()
+	if index > 0 && index < len(monitors) {
+		return monitors[index]
+	}
+	return glfw.GetPrimaryMonitor()
+}
+
+// ApplyConfig changes the window without recreating it, so the GL context is kept.
+// The context must be current.
+func (w *glfwWindow) ApplyConfig(old, config Config) {
+	if config.Title != old.Title {
+		w.SetTitle(config.Title)
+	}
+
+	switch config.DisplayMode {
+	case DisplayWindowed:
+		if old.DisplayMode != DisplayWindowed {
+			w.SetMonitor(nil, w.windowedX, w.windowedY, config.Width, config.Height, 0)
+		} else if config.Width != old.Width || config.Height != old.Height {
+			w.SetSize(config.Width, config.Height)
+		}
+	case DisplayFullscreen, DisplayBorderless:
+		if old.DisplayMode == DisplayWindowed {
+			w.windowedX, w.windowedY = w.GetPos()
+		}
+		monitor := glfwMonitor(config.Monitor)
+		mode := monitor.GetVideoMode()
+		if config.DisplayMode == DisplayFullscreen {
+			w.SetMonitor(monitor, 0, 0, config.Width, config.Height, mode.RefreshRate)
+		} else {
+			w.SetMonitor(monitor, 0, 0, mode.Width, mode.Height, mode.RefreshRate)
+		}
+	}
+
+	w.SetSizeLimits(glfwSizeLimit(config.MinWidth), glfwSizeLimit(config.MinHeight),
+		glfwSizeLimit(config.MaxWidth), glfwSizeLimit(config.MaxHeight))
+	if config.VSync {
+		glfw.SwapInterval(1)
+	} else {
+		glfw.SwapInterval(0)
+	}
+}
+
+func (w *glfwWindow) Graphics() *graphics.Context {
+	return w.graphics
+}
@@ -0,0 +1,222 @@
+package windows
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Input recordings are stored as JSON lines: a header line followed by one line per frame.
+// New optional fields may be added without changing the version,
+// the version is only bumped when old recordings can't be replayed as they are.
+const (
+	recordingFormat  = "krapulaengine2-input"
+	recordingVersion = 1
+)
+
+type recordingHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+// RecordedFrame is the input the window received during one frame
+type RecordedFrame struct {
+	Timedelta   float64              `json:"dt"`
+	KeyEvents   []KeyboardInputEvent `json:"keys,omitempty"`
+	MouseEvents []MouseInputEvent    `json:"mouse,omitempty"`
+
+	CharEvents   []CharInputEvent   `json:"chars,omitempty"`
+	ScrollEvents []ScrollInputEvent `json:"scroll,omitempty"`
+	CursorEvents []CursorEnterEvent `json:"cursor,omitempty"`
+	DropEvents   []DropEvent        `json:"drops,omitempty"`
+	CursorX      float32            `json:"x"`
+	CursorY      float32            `json:"y"`
+
+	GamepadConnectionEvents []GamepadConnectionEvent `json:"gamepadConnections,omitempty"`
+	GamepadButtonEvents     []GamepadButtonEvent     `json:"gamepadButtons,omitempty"`
+	Gamepads                []GamepadState           `json:"gamepads,omitempty"`
+}
+
+// InputRecorder writes input frames to a recording
+type InputRecorder struct {
+	encoder *json.Encoder
+	frames  int
+}
+
+// NewInputRecorder writes the recording header and returns a recorder writing to out
+func NewInputRecorder(out io.Writer) (*InputRecorder, error) {
+	r := new(InputRecorder)
+	r.encoder = json.NewEncoder(out)
+	if err := r.encoder.Encode(recordingHeader{recordingFormat, recordingVersion}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordFrame writes one frame to the recording
+func (r *InputRecorder) RecordFrame(frame RecordedFrame) error {
+	r.frames++
+	return r.encoder.Encode(frame)
+}
+
+// Frames returns the number of recorded frames
+func (r *InputRecorder) Frames() int {
+	return r.frames
+}
+
+// InputReplayer reads input frames from a recording
+type InputReplayer struct {
+	decoder *json.Decoder
+	version int
+}
+
+// NewInputReplayer reads and validates the recording header
+func NewInputReplayer(in io.Reader) (*InputReplayer, error) {
+	p := new(InputReplayer)
+	p.decoder = json.NewDecoder(in)
+	var header recordingHeader
+	if err := p.decoder.Decode(&header); err != nil {
+		return nil, fmt.Errorf("can't read input recording header: %v", err)
+	}
+	if header.Format != recordingFormat {
+		return nil, fmt.Errorf("not an input recording: %q", header.Format)
+	}
+	if header.Version < 1 || header.Version > recordingVersion {
+		return nil, fmt.Errorf("unsupported input recording version %v", header.Version)
+	}
+	p.version = header.Version
+	return p, nil
+}
+
+// Version returns the format version of the recording
+func (p *InputReplayer) Version() int {
+	return p.version
+}
+
+// NextFrame returns the next recorded frame. Returns io.EOF after the last frame.
+func (p *InputReplayer) NextFrame() (RecordedFrame, error) {
+	var frame RecordedFrame
+	err := p.decoder.Decode(&frame)
+	return frame, err
+}
+
+// StartRecording records all input and frame times of the window to out until StopRecording is called.
+func (w *Window) StartRecording(out io.Writer) error {
+	recorder, err := NewInputRecorder(out)
+	if err != nil {
+		return err
+	}
+	w.recorder = recorder
+	return nil
+}
+
+// StopRecording stops recording input
+func (w *Window) StopRecording() {
+	w.recorder = nil
+}
+
+// IsRecording returns true if the window is recording input
+func (w *Window) IsRecording() bool {
+	return w.recorder != nil
+}
+
+// StartReplay makes the main loop take its input and frame times from a recording instead of the user.
+// Live input is ignored while replaying. When the recording ends the main loop exits if exitWhenDone is set,
+// otherwise it goes back to live input.
+func (w *Window) StartReplay(in io.Reader, exitWhenDone bool) error {
+	replayer, err := NewInputReplayer(in)
+	if err != nil {
+		return err
+	}
+	w.replayer = replayer
+	w.exitAfterReplay = exitWhenDone
+	return nil
+}
+
+// IsReplaying returns true if the window takes its input from a recording
+func (w *Window) IsReplaying() bool {
+	return w.replayer != nil
+}
+
+// recordOrReplay records the input of this frame, or replaces it with the next recorded frame.
+// Returns the timedelta to use for the frame.
+func (w *Window) recordOrReplay(timedelta float64) float64 {
+	if w.replayer != nil {
+		frame, err := w.replayer.NextFrame()
+		if err != nil {
+			if err != io.EOF {
+				fmt.Println("input replay failed: " + err.Error())
+			}
+			w.replayer = nil
+			w.setFrameInput(RecordedFrame{})
+			if w.exitAfterReplay {
+				w.quit = true
+			}
+			return timedelta
+		}
+		w.setFrameInput(frame)
+		return frame.Timedelta
+	}
+
+	if w.recorder != nil {
+		err := w.recorder.RecordFrame(RecordedFrame{
+			Timedelta:   timedelta,
+			KeyEvents:   w.keyEvents,
+			MouseEvents: w.mouseEvents,
+
+			CharEvents:   w.charEvents,
+			ScrollEvents: w.scrollEvents,
+			CursorEvents: w.cursorEvents,
+			DropEvents:   w.dropEvents,
+			CursorX:      w.mouseX,
+			CursorY:      w.mouseY,
+
+			GamepadConnectionEvents: w.gamepads.connectionEvents,
+			GamepadButtonEvents:     w.gamepads.buttonEvents,
+			Gamepads:                w.gamepads.states,
+		})
+		if err != nil {
+			fmt.Println("input recording failed: " + err.Error())
+			w.recorder = nil
+		}
+	}
+	return timedelta
+}
+
+// Replay runs a recording through the scenes of the window without a real window or rendering.
+// Scenes are inited first if necessary. It is meant for running scenes in tests.
+func (w *Window) Replay(in io.Reader) error {
+	replayer, err := NewInputReplayer(in)
+	if err != nil {
+		return err
+	}
+	if !w.running {
+		w.initScenes()
+	}
+	w.quit = false
+	for !w.quit {
+		frame, err := replayer.NextFrame()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		w.setFrameInput(frame)
+		w.dispatchInput()
+		w.advance(frame.Timedelta)
+	}
+	return nil
+}
+
+func (w *Window) setFrameInput(frame RecordedFrame) {
+	w.keyEvents = append(w.keyEvents[:0], frame.KeyEvents...)
+	w.mouseEvents = append(w.mouseEvents[:0], frame.MouseEvents...)
+	w.charEvents = append(w.charEvents[:0], frame.CharEvents...)
+	w.scrollEvents = append(w.scrollEvents[:0], frame.ScrollEvents...)
+	w.cursorEvents = append(w.cursorEvents[:0], frame.CursorEvents...)
+	w.dropEvents = append(w.dropEvents[:0], frame.DropEvents...)
+	w.mouseX, w.mouseY = frame.CursorX, frame.CursorY
+	w.gamepads.connectionEvents = append(w.gamepads.connectionEvents[:0], frame.GamepadConnectionEvents...)
+	w.gamepads.buttonEvents = append(w.gamepads.buttonEvents[:0], frame.GamepadButtonEvents...)
+	w.gamepads.states = frame.Gamepads
+}
@@ -0,0 +1,93 @@
+package windows
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+type recordingTestScene struct {
+	SimpleSceneImpl
+	keyEvents   []KeyboardInputEvent
+	mouseEvents []MouseInputEvent
+	time        float64
+	leftTime    float64
+}
+
+func (s *recordingTestScene) Init() {
+	s.SetState(StateInited)
+}
+
+func (s *recordingTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	s.keyEvents = append(s.keyEvents, keyEvents...)
+	s.mouseEvents = append(s.mouseEvents, mouseEvents...)
+	return WindowActionNone
+}
+
+func (s *recordingTestScene) Tick(timedelta float64, keyStates []bool) {
+	s.time += timedelta
+	if keyStates[glfw.KeyLeft] {
+		s.leftTime += timedelta
+	}
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	frames := []RecordedFrame{
+		{Timedelta: 0.016, KeyEvents: []KeyboardInputEvent{{glfw.KeyLeft, 105, glfw.Press, 0}}},
+		{Timedelta: 0.017, MouseEvents: []MouseInputEvent{{glfw.MouseButtonLeft, glfw.Press, glfw.ModShift, 12.5, 30}}},
+		{Timedelta: 0.015, KeyEvents: []KeyboardInputEvent{{glfw.KeyLeft, 105, glfw.Release, 0}}},
+		{Timedelta: 0.1},
+	}
+
+	// record through the same path as the main loop
+	recording := new(bytes.Buffer)
+	w := NewWindow(800, 600, "test")
+	if err := w.StartRecording(recording); err != nil {
+		t.Fatal(err)
+	}
+	for _, frame := range frames {
+		w.setFrameInput(frame)
+		w.recordOrReplay(frame.Timedelta)
+	}
+	if w.recorder.Frames() != len(frames) {
+		t.Errorf("recorded %v frames, expected %v", w.recorder.Frames(), len(frames))
+	}
+
+	scene := new(recordingTestScene)
+	w = NewWindow(800, 600, "test")
+	w.AddScene("test", scene)
+	if err := w.Replay(recording); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(scene.keyEvents, append(frames[0].KeyEvents, frames[2].KeyEvents...)) {
+		t.Errorf("replayed key events differ: %v", scene.keyEvents)
+	}
+	if !reflect.DeepEqual(scene.mouseEvents, frames[1].MouseEvents) {
+		t.Errorf("replayed mouse events differ: %v", scene.mouseEvents)
+	}
+	expectedTime := 0.0
+	for _, frame := range frames {
+		expectedTime += frame.Timedelta
+	}
+	if scene.time != expectedTime {
+		t.Errorf("replayed time differs: %v", scene.time)
+	}
+	if scene.leftTime != frames[0].Timedelta+frames[1].Timedelta {
+		t.Errorf("key states weren't replayed, left was held for %v", scene.leftTime)
+	}
+}
+
+func TestReplayRejectsUnknownVersion(t *testing.T) {
+	_, err := NewInputReplayer(strings.NewReader(`{"format":"krapulaengine2-input","version":99}`))
+	if err == nil {
+		t.Errorf("expected an error for an unsupported version")
+	}
+	_, err = NewInputReplayer(strings.NewReader(`{"something":"else"}`))
+	if err == nil {
+		t.Errorf("expected an error for a file that isn't a recording")
+	}
+}
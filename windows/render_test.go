@@ -0,0 +1,41 @@
+package windows
+
+import (
+	"reflect"
+	"testing"
+)
+
+type renderTestScene struct {
+	stackTestScene
+	name     string
+	rendered *[]string
+}
+
+func (s *renderTestScene) Render() {
+	*s.rendered = append(*s.rendered, s.name)
+}
+
+func TestRenderOrder(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	var rendered []string
+	newScene := func(name string) *renderTestScene {
+		return &renderTestScene{stackTestScene{transparent: true}, name, &rendered}
+	}
+	w.AddScene("game", newScene("game"))
+	w.AddScene("pause", newScene("pause"))
+	w.PushScene("pause")
+	w.AddOverlayAt("hud", newScene("hud"), 1)
+	w.AddOverlayAt("console", newScene("console"), 2)
+	w.AddOverlayAt("hidden", newScene("hidden"), 3)
+	w.HideOverlay("hidden")
+	w.initScenes()
+
+	w.renderScenes()
+	rendered = append(rendered, "groups")
+	w.renderOverlays()
+
+	expected := []string{"game", "pause", "groups", "hud", "console"}
+	if !reflect.DeepEqual(rendered, expected) {
+		t.Errorf("expected %v, got %v", expected, rendered)
+	}
+}
@@ -1,5 +1,9 @@
 package windows
 
+import (
+	"fmt"
+)
+
 // states
 const (
 	StateUninited   = iota
@@ -9,6 +13,50 @@ const (
 	StateTerminated = iota
 )
 
+var stateNames = map[int]string{
+	StateUninited:   "Uninited",
+	StateInited:     "Inited",
+	StateRunning:    "Running",
+	StatePaused:     "Paused",
+	StateTerminated: "Terminated",
+}
+
+// StateName returns the name of a scene state
+func StateName(state int) string {
+	if name, ok := stateNames[state]; ok {
+		return name
+	}
+	return fmt.Sprintf("State(%d)", state)
+}
+
+// validTransitions maps each state to the states it can change to
+var validTransitions = map[int][]int{
+	StateUninited:   {StateInited, StateTerminated},
+	StateInited:     {StateRunning, StateTerminated},
+	StateRunning:    {StatePaused, StateTerminated},
+	StatePaused:     {StateRunning, StateTerminated},
+	StateTerminated: {StateInited},
+}
+
+// IsValidTransition returns true if a scene may change from one state to the other
+func IsValidTransition(from, to int) bool {
+	for _, valid := range validTransitions[from] {
+		if valid == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned when a scene is asked to make a transition its state doesn't allow
+type TransitionError struct {
+	From, To int
+}
+
+func (e *TransitionError) Error() string {
+	return "invalid scene state transition from " + StateName(e.From) + " to " + StateName(e.To)
+}
+
 // Scene are responsible for handling input and rendering their content.
 // They can be in the following states:
 // 1. Uninited - only bare minimum setup at this point
@@ -16,6 +64,7 @@ const (
 // 3. Running - Running normally
 // 4. Paused - Paused, can be resumed to return to the Running state
 // 5. Terminated - all resources should be released
+// Run, Pause, Resume and Exit return a *TransitionError if the current state doesn't allow the transition.
 type Scene interface {
 	// Window creates an input channel that is passed to the scene if necessary
 	// All relevant input will be sent to it.
@@ -29,6 +78,9 @@ type Scene interface {
 	// Update the game frame and process time-dependant input
 	Tick(timedelta float64, keyStates []bool)
 
+	// Render the scene. Active scenes are rendered before the render groups, overlays after them.
+	Render()
+
 	// Some scenes might want to ignore input or pause if they aren't focused
 	SetFocused(isFocused bool)
 	IsFocused() bool
@@ -38,29 +90,43 @@ type Scene interface {
 	// Returns true unless the scene is in state STATE_UNINITED or STATE_TERMINATED
 	IsInited() bool
 
-	// Set the scene in running mode
-	Run()
+	// Set an inited scene in running mode
+	Run() error
 	// Returns true if the scene is in state STATE_RUNNING
 	IsRunning() bool
 
 	// Pause the scene
-	Pause()
+	Pause() error
+	// Resume a paused scene
+	Resume() error
 	// Returns true if the scene is in state STATE_PAUSED
 	IsPaused() bool
 
-	Exit()
+	Exit() error
 	// returns true if this scene is in STATE_TERMINATED.
 	IsTerminated() bool
 }
 
+// TransitionObserver is told about every state change of a scene
+type TransitionObserver func(from, to int)
+
 // BasicSceneImpl is a partial Scene implementation that implements shared basic fields and functionality
 // You still need to implement the following functions yourself:
 // Init()
 // HandleInput(key_events []KeyboardInputEvent, mouse_events []MouseInputEvent) WindowAction
 // Tick(timedelta float64, key_states []bool)
+// Render() can be implemented by scenes that draw something themselves.
 type BasicSceneImpl struct {
-	focused bool
-	state   int
+	focused   bool
+	state     int
+	onEnter   map[int][]func()
+	onExit    map[int][]func()
+	observers []TransitionObserver
+	scheduler *Scheduler
+}
+
+// Render does nothing
+func (s *BasicSceneImpl) Render() {
 }
 
 // SetFocused ...
@@ -93,9 +159,69 @@ func (s *BasicSceneImpl) IsTerminated() bool {
 	return s.state == StateTerminated
 }
 
-// SetState sets state
+// State returns the current state
+func (s *BasicSceneImpl) State() int {
+	return s.state
+}
+
+// SetState sets the state without validating the transition. The hooks and observers are still called.
 func (s *BasicSceneImpl) SetState(state int) {
+	from := s.state
+	if from == state {
+		return
+	}
+	for _, hook := range s.onExit[from] {
+		hook()
+	}
 	s.state = state
+	for _, hook := range s.onEnter[state] {
+		hook()
+	}
+	for _, observer := range s.observers {
+		observer(from, state)
+	}
+	if state == StateTerminated && s.scheduler != nil {
+		s.scheduler.CancelAll()
+	}
+}
+
+// Scheduler returns the timers and coroutines of the scene. It is updated before each Tick
+// and stops while the scene is paused. Everything scheduled is canceled when the scene exits.
+func (s *BasicSceneImpl) Scheduler() *Scheduler {
+	if s.scheduler == nil {
+		s.scheduler = NewScheduler()
+	}
+	return s.scheduler
+}
+
+// Transition changes the state if the transition is valid, otherwise it returns a *TransitionError
+func (s *BasicSceneImpl) Transition(state int) error {
+	if !IsValidTransition(s.state, state) {
+		return &TransitionError{s.state, state}
+	}
+	s.SetState(state)
+	return nil
+}
+
+// OnEnter adds a hook that is called when the scene enters the state
+func (s *BasicSceneImpl) OnEnter(state int, hook func()) {
+	if s.onEnter == nil {
+		s.onEnter = make(map[int][]func())
+	}
+	s.onEnter[state] = append(s.onEnter[state], hook)
+}
+
+// OnExit adds a hook that is called when the scene leaves the state
+func (s *BasicSceneImpl) OnExit(state int, hook func()) {
+	if s.onExit == nil {
+		s.onExit = make(map[int][]func())
+	}
+	s.onExit[state] = append(s.onExit[state], hook)
+}
+
+// AddObserver adds an observer that is told about every state change
+func (s *BasicSceneImpl) AddObserver(observer TransitionObserver) {
+	s.observers = append(s.observers, observer)
 }
 
 // SimpleSceneImpl is a simple scene implementation to use when you want default state transitions and input
@@ -108,25 +234,28 @@ func (s *SimpleSceneImpl) AcceptsInput() bool {
 	return true
 }
 
-// Run checks that the scene is inited and sets the state to running
-func (s *SimpleSceneImpl) Run() {
-	if !s.IsInited() {
-		panic("Cannot run scene that hasn't been inited. state: " + string(s.state))
-	} else {
-		s.SetState(StateRunning)
+// Run sets an inited scene to running
+func (s *SimpleSceneImpl) Run() error {
+	if s.state != StateInited {
+		return &TransitionError{s.state, StateRunning}
 	}
+	return s.Transition(StateRunning)
+}
+
+// Pause pauses a running scene
+func (s *SimpleSceneImpl) Pause() error {
+	return s.Transition(StatePaused)
 }
 
-// Pause pauses the scene
-func (s *SimpleSceneImpl) Pause() {
-	if !s.IsRunning() {
-		panic("Cannot pause scene that isn't running")
-	} else {
-		s.SetState(StatePaused)
+// Resume resumes a paused scene
+func (s *SimpleSceneImpl) Resume() error {
+	if s.state != StatePaused {
+		return &TransitionError{s.state, StateRunning}
 	}
+	return s.Transition(StateRunning)
 }
 
 // Exit terminates the scene
-func (s *SimpleSceneImpl) Exit() {
-	s.SetState(StateTerminated)
+func (s *SimpleSceneImpl) Exit() error {
+	return s.Transition(StateTerminated)
 }
@@ -0,0 +1,193 @@
+package windows
+
+import (
+	"fmt"
+)
+
+// TransparentScene can be implemented by scenes that let the scene below them
+// in the stack keep running, e.g. a pause menu drawn on top of the game.
+type TransparentScene interface {
+	// Returns true if the scene below should still tick and receive input
+	IsTransparent() bool
+}
+
+func isTransparent(scene Scene) bool {
+	if t, ok := scene.(TransparentScene); ok {
+		return t.IsTransparent()
+	}
+	return false
+}
+
+// activeScenes returns the scenes that should tick and receive input, top first.
+// That is the top scene and, as long as the scene above is transparent, the ones below it.
+func (w *Window) activeScenes() []Scene {
+	active := make([]Scene, 0, 2)
+	for i := len(w.sceneStack) - 1; i >= 0; i-- {
+		scene := w.scenes[w.sceneStack[i]]
+		active = append(active, scene)
+		if !isTransparent(scene) {
+			break
+		}
+	}
+	return active
+}
+
+func (w *Window) getScene(id string) Scene {
+	scene, exists := w.scenes[id]
+	if !exists {
+		panic("Scene not added to window: " + id)
+	}
+	return scene
+}
+
+// assertNotOnStack panics if id is one of the given stack entries
+func assertNotOnStack(stack []string, id string) {
+	for _, stacked := range stack {
+		if stacked == id {
+			panic("Scene is already on the scene stack: " + id)
+		}
+	}
+}
+
+// updateSceneStates pauses the scenes that are no longer active and runs the ones that became active.
+// Nothing is done before the main loop has inited the scenes.
+func (w *Window) updateSceneStates(previouslyActive []Scene) {
+	if !w.running {
+		return
+	}
+	active := w.activeScenes()
+	for _, scene := range previouslyActive {
+		if !containsScene(active, scene) && scene.IsRunning() {
+			logSceneError(scene.Pause())
+		}
+	}
+	for _, scene := range active {
+		if !scene.IsInited() && w.initScene(scene) != nil {
+			continue
+		}
+		if !scene.IsRunning() {
+			logSceneError(runScene(scene))
+		}
+	}
+}
+
+func containsScene(scenes []Scene, scene Scene) bool {
+	for _, s := range scenes {
+		if s == scene {
+			return true
+		}
+	}
+	return false
+}
+
+// PushScene puts an added scene on top of the scene stack.
+// The scenes it covers are paused unless it is transparent.
+func (w *Window) PushScene(id string) {
+	w.getScene(id)
+	assertNotOnStack(w.sceneStack, id)
+	previouslyActive := w.activeScenes()
+	w.sceneStack = append(w.sceneStack, id)
+	w.updateSceneStates(previouslyActive)
+}
+
+// PopScene removes the top scene from the stack, pauses it and resumes the scenes below.
+// Returns the removed scene, or nil if the stack was empty.
+func (w *Window) PopScene() Scene {
+	if len(w.sceneStack) == 0 {
+		return nil
+	}
+	previouslyActive := w.activeScenes()
+	top := w.sceneStack[len(w.sceneStack)-1]
+	w.sceneStack = w.sceneStack[:len(w.sceneStack)-1]
+	w.updateSceneStates(previouslyActive)
+	return w.scenes[top]
+}
+
+// ReplaceScene replaces the top scene of the stack, or pushes the scene if the stack is empty.
+// Returns the replaced scene, if any.
+func (w *Window) ReplaceScene(id string) Scene {
+	w.getScene(id)
+	var replaced Scene
+	previouslyActive := w.activeScenes()
+	if len(w.sceneStack) > 0 {
+		top := w.sceneStack[len(w.sceneStack)-1]
+		if top == id {
+			return nil
+		}
+		// check the rest of the stack before changing it, so a panic leaves it intact
+		assertNotOnStack(w.sceneStack[:len(w.sceneStack)-1], id)
+		replaced = w.scenes[top]
+		w.sceneStack = w.sceneStack[:len(w.sceneStack)-1]
+	}
+	w.sceneStack = append(w.sceneStack, id)
+	w.updateSceneStates(previouslyActive)
+	return replaced
+}
+
+// CurrentScene returns the scene on top of the stack, or nil if there is none
+func (w *Window) CurrentScene() Scene {
+	if len(w.sceneStack) == 0 {
+		return nil
+	}
+	return w.scenes[w.sceneStack[len(w.sceneStack)-1]]
+}
+
+// PushScene puts an added scene on top of the main window's scene stack.
+func PushScene(id string) {
+	MainWindow.PushScene(id)
+}
+
+// PopScene removes the top scene from the main window's scene stack.
+func PopScene() Scene {
+	return MainWindow.PopScene()
+}
+
+// ReplaceScene replaces the top scene of the main window's scene stack.
+func ReplaceScene(id string) Scene {
+	return MainWindow.ReplaceScene(id)
+}
+
+// CurrentScene returns the scene on top of the main window's scene stack.
+func CurrentScene() Scene {
+	return MainWindow.CurrentScene()
+}
+
+// runScene runs an inited scene or resumes a paused one
+func runScene(scene Scene) error {
+	if scene.IsPaused() {
+		return scene.Resume()
+	}
+	return scene.Run()
+}
+
+// logSceneError prints errors of state changes made by the window.
+// They are bugs in the scenes, but not worth stopping the game for.
+func logSceneError(err error) {
+	if err != nil {
+		fmt.Println("Scene state error:", err)
+	}
+}
+
+// observableScene is implemented by scenes embedding BasicSceneImpl
+type observableScene interface {
+	AddObserver(observer TransitionObserver)
+}
+
+// SceneObserver is told about the state changes of the scenes and overlays of a window
+type SceneObserver func(id string, scene Scene, from, to int)
+
+// SetSceneObserver sets the observer of the scene state changes, e.g. for logging. nil removes it.
+// Only scenes embedding BasicSceneImpl can be observed.
+func (w *Window) SetSceneObserver(observer SceneObserver) {
+	w.sceneObserver = observer
+}
+
+func (w *Window) observeScene(id string, scene Scene) {
+	if observable, ok := scene.(observableScene); ok {
+		observable.AddObserver(func(from, to int) {
+			if w.sceneObserver != nil {
+				w.sceneObserver(id, scene, from, to)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package windows
+
+import (
+	"testing"
+)
+
+type stackTestScene struct {
+	SimpleSceneImpl
+	transparent bool
+	ticks       int
+	inputs      int
+}
+
+func newStackTestScene(transparent bool) *stackTestScene {
+	scene := new(stackTestScene)
+	scene.transparent = transparent
+	return scene
+}
+
+func (s *stackTestScene) Init() {
+	s.SetState(StateInited)
+}
+
+func (s *stackTestScene) IsTransparent() bool {
+	return s.transparent
+}
+
+func (s *stackTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	s.inputs++
+	return WindowActionNone
+}
+
+func (s *stackTestScene) Tick(timedelta float64, keyStates []bool) {
+	s.ticks++
+}
+
+func TestSceneStack(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	menu := newStackTestScene(false)
+	game := newStackTestScene(false)
+	pause := newStackTestScene(true)
+	w.AddScene("menu", menu)
+	w.AddScene("game", game)
+	w.AddScene("pause", pause)
+
+	if w.CurrentScene() != menu {
+		t.Fatalf("first added scene should be current")
+	}
+	w.initScenes()
+	if !menu.IsRunning() || game.IsRunning() || !game.IsInited() {
+		t.Fatalf("only the top scene should run. menu: %v, game: %v", menu.state, game.state)
+	}
+
+	w.ReplaceScene("game")
+	if !menu.IsPaused() || !game.IsRunning() {
+		t.Errorf("replace should pause menu and run game. menu: %v, game: %v", menu.state, game.state)
+	}
+
+	w.dispatchInput()
+	w.tick(0.1)
+	if menu.ticks != 0 || menu.inputs != 0 || game.ticks != 1 || game.inputs != 1 {
+		t.Errorf("only the game should tick. menu: %v/%v, game: %v/%v", menu.ticks, menu.inputs, game.ticks, game.inputs)
+	}
+
+	// the pause menu is transparent so the game keeps running below it
+	w.PushScene("pause")
+	w.tick(0.1)
+	if !game.IsRunning() || pause.ticks != 1 || game.ticks != 2 {
+		t.Errorf("transparent scene should keep the game running. game: %v ticks: %v", game.state, game.ticks)
+	}
+
+	pause.transparent = false
+	w.PopScene()
+	w.PushScene("pause")
+	if !game.IsPaused() {
+		t.Errorf("game should be paused below an opaque scene, was %v", game.state)
+	}
+
+	if popped := w.PopScene(); popped != pause {
+		t.Errorf("popped the wrong scene")
+	}
+	if !pause.IsPaused() || !game.IsRunning() {
+		t.Errorf("pop should pause the popped scene and resume the game. pause: %v, game: %v", pause.state, game.state)
+	}
+}
+
+func TestSceneStackDuplicatePush(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	w.AddScene("menu", newStackTestScene(false))
+	defer func() {
+		if recover() == nil {
+			t.Errorf("pushing a scene twice should panic")
+		}
+	}()
+	w.PushScene("menu")
+}
+
+func TestSceneStackReplaceWithStackedScene(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	w.AddScene("game", newStackTestScene(false))
+	w.AddScene("pause", newStackTestScene(false))
+	// the first added scene is pushed
+	w.PushScene("pause")
+	defer func() {
+		if recover() == nil {
+			t.Errorf("replacing with a scene further down the stack should panic")
+		}
+		if len(w.sceneStack) != 2 || w.sceneStack[0] != "game" || w.sceneStack[1] != "pause" {
+			t.Errorf("the failed replace should leave the stack unchanged, got %v", w.sceneStack)
+		}
+	}()
+	w.ReplaceScene("game")
+}
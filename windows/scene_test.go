@@ -1,6 +1,7 @@
 package windows
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -34,3 +35,105 @@ func TestSimpleSceneImpl(t *testing.T) {
 		t.Errorf("scene was %v. expected %v", scene.state, StateRunning)
 	}
 }
+
+type lifecycleTestScene struct {
+	SimpleSceneImpl
+}
+
+func (s *lifecycleTestScene) Init() {
+	s.SetState(StateInited)
+}
+
+func (s *lifecycleTestScene) HandleInput(keyEvents []KeyboardInputEvent, mouseEvents []MouseInputEvent) WindowAction {
+	return WindowActionNone
+}
+
+func (s *lifecycleTestScene) Tick(timedelta float64, keyStates []bool) {}
+
+func TestSceneTransitions(t *testing.T) {
+	s := new(lifecycleTestScene)
+	if err := s.Run(); err == nil {
+		t.Errorf("an uninited scene shouldn't run")
+	}
+	s.Init()
+	if err := s.Resume(); err == nil {
+		t.Errorf("only paused scenes can be resumed")
+	}
+	if err := s.Run(); err != nil || !s.IsRunning() {
+		t.Errorf("an inited scene should run: %v", err)
+	}
+	if err := s.Run(); err == nil {
+		t.Errorf("a running scene shouldn't run again")
+	}
+	if err := s.Pause(); err != nil || !s.IsPaused() {
+		t.Errorf("a running scene should pause: %v", err)
+	}
+	if err := s.Resume(); err != nil || !s.IsRunning() {
+		t.Errorf("a paused scene should resume: %v", err)
+	}
+	if err := s.Exit(); err != nil || !s.IsTerminated() {
+		t.Errorf("a running scene should exit: %v", err)
+	}
+
+	err := s.Pause()
+	transitionError, ok := err.(*TransitionError)
+	if !ok || transitionError.From != StateTerminated || transitionError.To != StatePaused {
+		t.Fatalf("expected a transition error, got %v", err)
+	}
+	if err.Error() != "invalid scene state transition from Terminated to Paused" {
+		t.Errorf("unexpected error message %q", err.Error())
+	}
+}
+
+func TestSceneHooksAndObserver(t *testing.T) {
+	var calls []string
+	s := new(lifecycleTestScene)
+	s.OnEnter(StateRunning, func() { calls = append(calls, "enter running") })
+	s.OnExit(StateRunning, func() { calls = append(calls, "exit running") })
+	s.AddObserver(func(from, to int) {
+		calls = append(calls, StateName(from)+"->"+StateName(to))
+	})
+
+	s.Init()
+	s.Run()
+	s.Pause()
+	s.Pause()
+
+	expected := []string{
+		"Uninited->Inited",
+		"enter running", "Inited->Running",
+		"exit running", "Running->Paused",
+	}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+}
+
+func TestWindowSceneObserver(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	var changes []string
+	w.SetSceneObserver(func(id string, scene Scene, from, to int) {
+		changes = append(changes, id+":"+StateName(to))
+	})
+	w.AddScene("game", new(lifecycleTestScene))
+	w.AddScene("pause", new(lifecycleTestScene))
+	w.initScenes()
+	w.PushScene("pause")
+	w.PopScene()
+
+	expected := []string{
+		"game:Inited", "pause:Inited", "game:Running",
+		"game:Paused", "pause:Running",
+		"pause:Paused", "game:Running",
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, changes)
+	}
+	// the scenes are inited in map order
+	if !(changes[0] == expected[0] && changes[1] == expected[1] || changes[0] == expected[1] && changes[1] == expected[0]) {
+		t.Errorf("expected both scenes inited first, got %v", changes[:2])
+	}
+	if !reflect.DeepEqual(changes[2:], expected[2:]) {
+		t.Errorf("expected %v, got %v", expected[2:], changes[2:])
+	}
+}
@@ -0,0 +1,266 @@
+package windows
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Scheduler runs timers and coroutines on the tick thread. Every scene embedding BasicSceneImpl
+// has one, which is updated before each Tick of the scene and so stops while the scene is paused.
+type Scheduler struct {
+	time       float64
+	timers     []*Timer
+	coroutines []*Coroutine
+}
+
+// ScheduledScene is implemented by scenes with a scheduler, e.g. by embedding BasicSceneImpl
+type ScheduledScene interface {
+	Scheduler() *Scheduler
+}
+
+// Timer is a function scheduled with After or Every
+type Timer struct {
+	due      float64
+	interval float64
+	fn       func()
+	canceled bool
+}
+
+// Cancel stops the timer. It is safe to cancel a timer more than once.
+func (t *Timer) Cancel() {
+	t.canceled = true
+}
+
+// Active returns true until the timer has fired for the last time or has been canceled
+func (t *Timer) Active() bool {
+	return !t.canceled
+}
+
+// Coroutine is a script running on its own goroutine, but only while the scheduler is updated,
+// so it can use the state of its scene like Tick does. Graphics and window functions need the
+// locked main thread, so the script runs them with Do, which runs them on the tick thread.
+type Coroutine struct {
+	scheduler *Scheduler
+	resume    chan bool
+	yield     chan struct{}
+	wakeAt    float64
+	until     func() bool
+	running   bool
+	canceled  bool
+	done      bool
+	// set if the script panicked
+	err error
+	// function passed to Do, waiting to be run on the tick thread, and its panic
+	call      func()
+	callPanic interface{}
+}
+
+// NewScheduler creates a scheduler
+func NewScheduler() *Scheduler {
+	return new(Scheduler)
+}
+
+// Time returns the time in seconds the scheduler has been updated for
+func (s *Scheduler) Time() float64 {
+	return s.time
+}
+
+// After calls fn once after delay seconds
+func (s *Scheduler) After(delay float64, fn func()) *Timer {
+	t := &Timer{due: s.time + delay, fn: fn}
+	s.timers = append(s.timers, t)
+	return t
+}
+
+// Every calls fn every interval seconds, starting after the first interval
+func (s *Scheduler) Every(interval float64, fn func()) *Timer {
+	if interval <= 0 {
+		panic("Every needs a positive interval")
+	}
+	t := &Timer{due: s.time + interval, interval: interval, fn: fn}
+	s.timers = append(s.timers, t)
+	return t
+}
+
+// Go starts a coroutine. It starts running on the next update.
+func (s *Scheduler) Go(script func(co *Coroutine)) *Coroutine {
+	co := &Coroutine{
+		scheduler: s,
+		resume:    make(chan bool),
+		yield:     make(chan struct{}),
+		wakeAt:    s.time,
+	}
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				co.err = fmt.Errorf("coroutine panicked: %v\n%s", r, debug.Stack())
+			}
+			co.done = true
+			co.yield <- struct{}{}
+		}()
+		if !<-co.resume {
+			return
+		}
+		script(co)
+	}()
+	s.coroutines = append(s.coroutines, co)
+	return co
+}
+
+// Update advances the time, fires the due timers and resumes the coroutines that are done waiting.
+// Timers and coroutines added during the update start on the next one.
+// A coroutine that panics is stopped and the panic is returned as an error.
+func (s *Scheduler) Update(timedelta float64) error {
+	var err error
+	s.time += timedelta
+
+	timers := s.timers
+	for _, t := range timers {
+		for !t.canceled && t.due <= s.time {
+			t.fn()
+			if t.interval > 0 {
+				t.due += t.interval
+			} else {
+				t.canceled = true
+			}
+		}
+	}
+
+	coroutines := s.coroutines
+	for _, co := range coroutines {
+		if co.canceled || co.done || co.wakeAt > s.time {
+			continue
+		}
+		if co.until != nil && !co.until() {
+			continue
+		}
+		co.until = nil
+		co.step(true)
+		if co.err != nil && err == nil {
+			err = co.err
+		}
+	}
+
+	s.removeFinished()
+	return err
+}
+
+// removeFinished drops canceled timers and finished coroutines
+func (s *Scheduler) removeFinished() {
+	timers := make([]*Timer, 0, len(s.timers))
+	for _, t := range s.timers {
+		if t.Active() {
+			timers = append(timers, t)
+		}
+	}
+	s.timers = timers
+
+	coroutines := make([]*Coroutine, 0, len(s.coroutines))
+	for _, co := range s.coroutines {
+		if !co.Done() {
+			coroutines = append(coroutines, co)
+		}
+	}
+	s.coroutines = coroutines
+}
+
+// CancelAll cancels all timers and coroutines
+func (s *Scheduler) CancelAll() {
+	for _, t := range s.timers {
+		t.Cancel()
+	}
+	for _, co := range s.coroutines {
+		co.Cancel()
+	}
+	s.timers = nil
+	s.coroutines = nil
+}
+
+// step resumes the coroutine and waits until it waits again or ends.
+// Functions passed to Do meanwhile are run here, on the tick thread.
+func (co *Coroutine) step(resume bool) {
+	co.running = true
+	co.resume <- resume
+	<-co.yield
+	for co.call != nil {
+		call := co.call
+		co.call = nil
+		func() {
+			defer func() {
+				co.callPanic = recover()
+			}()
+			call()
+		}()
+		co.resume <- !co.canceled
+		<-co.yield
+	}
+	co.running = false
+}
+
+// Do runs fn on the tick thread and returns when it's done, so the coroutine can use render
+// groups, GL and windows. The coroutine goes on in the same update. A panic in fn is
+// raised in the coroutine.
+func (co *Coroutine) Do(fn func()) {
+	if co.canceled {
+		runtime.Goexit()
+	}
+	co.call = fn
+	co.yield <- struct{}{}
+	if !<-co.resume {
+		runtime.Goexit()
+	}
+	if p := co.callPanic; p != nil {
+		co.callPanic = nil
+		panic(p)
+	}
+}
+
+// Wait pauses the coroutine for the given number of seconds of scheduler time
+func (co *Coroutine) Wait(seconds float64) {
+	co.wakeAt = co.scheduler.time + seconds
+	co.suspend()
+}
+
+// WaitUntil pauses the coroutine until condition returns true. It is checked on every update.
+func (co *Coroutine) WaitUntil(condition func() bool) {
+	co.until = condition
+	co.suspend()
+}
+
+// Yield pauses the coroutine until the next update
+func (co *Coroutine) Yield() {
+	co.Wait(0)
+}
+
+func (co *Coroutine) suspend() {
+	if co.canceled {
+		runtime.Goexit()
+	}
+	co.yield <- struct{}{}
+	if !<-co.resume {
+		runtime.Goexit()
+	}
+}
+
+// Cancel stops the coroutine. Its deferred calls are run.
+// A coroutine canceling itself stops at its next wait.
+func (co *Coroutine) Cancel() {
+	if co.canceled || co.done {
+		return
+	}
+	co.canceled = true
+	if !co.running {
+		co.step(false)
+	}
+}
+
+// Done returns true when the coroutine has returned or has been canceled
+func (co *Coroutine) Done() bool {
+	return co.done || co.canceled
+}
+
+// Err returns the panic that stopped the coroutine as an error, or nil
+func (co *Coroutine) Err() error {
+	return co.err
+}
@@ -0,0 +1,155 @@
+package windows
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSchedulerTimers(t *testing.T) {
+	s := NewScheduler()
+	var calls []string
+	s.After(1, func() { calls = append(calls, "after") })
+	every := s.Every(0.5, func() { calls = append(calls, "every") })
+	canceled := s.After(0.2, func() { calls = append(calls, "canceled") })
+	canceled.Cancel()
+
+	s.Update(0.6)
+	s.Update(0.6)
+	every.Cancel()
+	s.Update(1)
+
+	expected := []string{"every", "after", "every"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected %v, got %v", expected, calls)
+	}
+	if len(s.timers) != 0 {
+		t.Errorf("finished timers should be dropped, got %v", len(s.timers))
+	}
+}
+
+func TestSchedulerCoroutines(t *testing.T) {
+	s := NewScheduler()
+	var steps []string
+	ready := false
+	co := s.Go(func(co *Coroutine) {
+		steps = append(steps, "start")
+		co.Wait(1)
+		steps = append(steps, "waited")
+		co.WaitUntil(func() bool { return ready })
+		steps = append(steps, "ready")
+	})
+
+	s.Update(0.1)
+	s.Update(0.5)
+	if !reflect.DeepEqual(steps, []string{"start"}) {
+		t.Errorf("the coroutine should be waiting, got %v", steps)
+	}
+	s.Update(0.5)
+	s.Update(0.5)
+	ready = true
+	s.Update(0.1)
+	if !reflect.DeepEqual(steps, []string{"start", "waited", "ready"}) || !co.Done() {
+		t.Errorf("the coroutine should have finished, got %v", steps)
+	}
+}
+
+func TestSchedulerCancelCoroutine(t *testing.T) {
+	s := NewScheduler()
+	cleanedUp := false
+	steps := 0
+	co := s.Go(func(co *Coroutine) {
+		defer func() { cleanedUp = true }()
+		for {
+			steps++
+			co.Yield()
+		}
+	})
+	s.Update(0.1)
+	s.Update(0.1)
+	co.Cancel()
+	s.Update(0.1)
+	if steps != 2 || !cleanedUp || !co.Done() {
+		t.Errorf("a canceled coroutine should stop and run its defers, got %v steps", steps)
+	}
+}
+
+func TestSchedulerPausesWithScene(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	scene := newStackTestScene(false)
+	w.AddScene("game", scene)
+	w.initScenes()
+	fired := 0
+	scene.Scheduler().Every(1, func() { fired++ })
+
+	w.tick(1)
+	scene.Pause()
+	w.tick(1)
+	scene.Resume()
+	w.tick(1)
+	if fired != 2 {
+		t.Errorf("the timer shouldn't run while the scene is paused, fired %v times", fired)
+	}
+
+	scene.Exit()
+	if len(scene.Scheduler().timers) != 0 {
+		t.Errorf("exiting should cancel the timers")
+	}
+}
+
+func TestSchedulerCoroutinePanic(t *testing.T) {
+	s := NewScheduler()
+	co := s.Go(func(co *Coroutine) {
+		co.Yield()
+		panic("script failed")
+	})
+	if err := s.Update(0.1); err != nil {
+		t.Errorf("the coroutine should not have failed yet: %v", err)
+	}
+	err := s.Update(0.1)
+	if err == nil || !strings.Contains(err.Error(), "script failed") {
+		t.Errorf("the panic should be returned by Update, got %v", err)
+	}
+	if !co.Done() || co.Err() != err {
+		t.Errorf("a panicked coroutine should be done and keep its error")
+	}
+	if err := s.Update(0.1); err != nil {
+		t.Errorf("the error should only be returned once, got %v", err)
+	}
+}
+
+// goroutineID returns the id of the calling goroutine from its stack trace
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	return strings.Fields(string(buf))[1]
+}
+
+func TestCoroutineDo(t *testing.T) {
+	s := NewScheduler()
+	tick := goroutineID()
+	var steps []string
+	s.Go(func(co *Coroutine) {
+		steps = append(steps, "start")
+		co.Do(func() {
+			if goroutineID() != tick {
+				t.Errorf("Do should run on the goroutine updating the scheduler")
+			}
+			steps = append(steps, "do")
+		})
+		steps = append(steps, "after")
+		co.Do(func() {
+			panic("upload failed")
+		})
+		steps = append(steps, "unreachable")
+	})
+
+	err := s.Update(0.1)
+	if !reflect.DeepEqual(steps, []string{"start", "do", "after"}) {
+		t.Errorf("Do should run during the update, got %v", steps)
+	}
+	if err == nil || !strings.Contains(err.Error(), "upload failed") {
+		t.Errorf("a panic in Do should stop the coroutine, got %v", err)
+	}
+}
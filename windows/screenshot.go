@@ -0,0 +1,85 @@
+package windows
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+	"github.com/krapulacoders/krapulaengine2/graphics"
+)
+
+// SetScreenshotKey makes pressing key with exactly the given modifiers save a
+// timestamped PNG of the window into dir. glfw.KeyUnknown disables the hotkey.
+func (w *Window) SetScreenshotKey(key glfw.Key, mods glfw.ModifierKey, dir string) {
+	w.screenshotKey = key
+	w.screenshotMods = mods
+	w.screenshotDir = dir
+}
+
+// TakeScreenshot saves a timestamped PNG of the next rendered frame into dir
+func (w *Window) TakeScreenshot(dir string) {
+	w.screenshotDir = dir
+	w.screenshotRequested = true
+}
+
+// LastScreenshot returns the file of the last screenshot saved, or "" if none has been saved
+func (w *Window) LastScreenshot() string {
+	return w.lastScreenshot
+}
+
+// checkScreenshotKey requests a screenshot when the hotkey was pressed this frame
+func (w *Window) checkScreenshotKey() {
+	if w.screenshotKey == glfw.KeyUnknown {
+		return
+	}
+	for _, event := range w.keyEvents {
+		if event.Key == w.screenshotKey && event.Action == glfw.Press && event.Mod == w.screenshotMods {
+			w.screenshotRequested = true
+		}
+	}
+}
+
+// saveRequestedScreenshot captures the rendered frame if a screenshot was requested.
+// It must be called before the buffers are swapped.
+func (w *Window) saveRequestedScreenshot() {
+	if !w.screenshotRequested {
+		return
+	}
+	w.screenshotRequested = false
+	file, err := saveScreenshot(graphics.CaptureFrame(), w.screenshotDir, time.Now())
+	if err != nil {
+		fmt.Println("Can't save screenshot:", err)
+		return
+	}
+	w.lastScreenshot = file
+}
+
+// saveScreenshot saves img as a PNG named after the time into dir and returns the file name
+func saveScreenshot(img image.Image, dir string, t time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	file := filepath.Join(dir, "screenshot-"+t.Format("20060102-150405.000")+".png")
+	return file, writePNG(file, img)
+}
+
+func writePNG(file string, img image.Image) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, img); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// SetScreenshotKey sets the screenshot hotkey of the main window
+func SetScreenshotKey(key glfw.Key, mods glfw.ModifierKey, dir string) {
+	MainWindow.SetScreenshotKey(key, mods, dir)
+}
@@ -0,0 +1,79 @@
+package windows
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-gl/glfw/v3.2/glfw"
+)
+
+func TestSaveScreenshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "screenshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(1, 0, color.RGBA{255, 0, 0, 255})
+	file, err := saveScreenshot(img, dir, time.Date(2017, 3, 4, 12, 30, 15, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Base(file) != "screenshot-20170304-123015.000.png" {
+		t.Errorf("unexpected file name %v", file)
+	}
+
+	in, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	loaded, err := png.Decode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r, _, _, _ := loaded.At(1, 0).RGBA(); r != 0xffff {
+		t.Errorf("expected the red pixel to be saved, got %v", loaded.At(1, 0))
+	}
+}
+
+func TestScreenshotKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "screenshots")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(64, 32, "headless")
+	defer func() { MainWindow = main }()
+	MainWindow.SetScreenshotKey(glfw.KeyF12, 0, dir)
+	MainWindow.AddScene("test", new(headlessTestScene))
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		switch frame {
+		case 1:
+			input.Key(glfw.KeyF12, glfw.Press, glfw.ModShift)
+		case 2:
+			input.Key(glfw.KeyF12, glfw.Press, 0)
+		case 3:
+			input.Close()
+		}
+	})
+	MainLoop()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.png"))
+	if len(files) != 1 || MainWindow.LastScreenshot() != files[0] {
+		t.Errorf("expected one screenshot, got %v", files)
+	}
+}
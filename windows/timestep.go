@@ -0,0 +1,83 @@
+package windows
+
+import (
+	"math"
+)
+
+// TimestepMode decides how the main loop advances the scenes
+type TimestepMode int
+
+// Timestep modes
+const (
+	// TimestepVariable ticks once per frame with the real frame time
+	TimestepVariable TimestepMode = iota
+	// TimestepFixed ticks at a fixed rate and lets rendering interpolate between ticks
+	TimestepFixed = iota
+)
+
+// DefaultMaxTicksPerFrame is the default cap for catch-up ticks in fixed timestep mode
+const DefaultMaxTicksPerFrame = 5
+
+// timestep turns frame times into scene ticks
+type timestep struct {
+	mode        TimestepMode
+	step        float64
+	maxSteps    int
+	accumulator float64
+}
+
+// advance adds the frame time and returns how many ticks to run, the timedelta of each tick
+// and how far (0-1) rendering is between the last tick and the next one.
+func (t *timestep) advance(frametime float64) (steps int, timedelta float64, alpha float64) {
+	if t.mode == TimestepVariable {
+		return 1, frametime, 1
+	}
+
+	t.accumulator += frametime
+	steps = int(t.accumulator / t.step)
+	if steps > t.maxSteps {
+		// We can't keep up. Drop the time we're behind instead of spiraling
+		// into longer and longer frames.
+		steps = t.maxSteps
+		t.accumulator = math.Mod(t.accumulator, t.step)
+	} else {
+		t.accumulator -= float64(steps) * t.step
+	}
+	return steps, t.step, t.accumulator / t.step
+}
+
+// SetFixedTimestep makes the window tick its scenes every step seconds,
+// running at most maxTicksPerFrame ticks per frame to catch up.
+func (w *Window) SetFixedTimestep(step float64, maxTicksPerFrame int) {
+	if step <= 0 {
+		panic("fixed timestep must be positive")
+	}
+	if maxTicksPerFrame < 1 {
+		maxTicksPerFrame = DefaultMaxTicksPerFrame
+	}
+	w.timestep = timestep{
+		mode:     TimestepFixed,
+		step:     step,
+		maxSteps: maxTicksPerFrame,
+	}
+}
+
+// SetVariableTimestep makes the window tick its scenes once per frame with the frame time. This is the default.
+func (w *Window) SetVariableTimestep() {
+	w.timestep = timestep{mode: TimestepVariable}
+}
+
+// GetTimestepMode returns the current timestep mode
+func (w *Window) GetTimestepMode() TimestepMode {
+	return w.timestep.mode
+}
+
+// SetFixedTimestep sets a fixed timestep for the main window
+func SetFixedTimestep(step float64, maxTicksPerFrame int) {
+	MainWindow.SetFixedTimestep(step, maxTicksPerFrame)
+}
+
+// SetVariableTimestep sets a variable timestep for the main window
+func SetVariableTimestep() {
+	MainWindow.SetVariableTimestep()
+}
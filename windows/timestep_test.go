@@ -0,0 +1,49 @@
+package windows
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVariableTimestep(t *testing.T) {
+	var ts timestep
+	steps, delta, alpha := ts.advance(0.033)
+	if steps != 1 || delta != 0.033 || alpha != 1 {
+		t.Errorf("variable timestep should tick once with the frame time. got %v, %v, %v", steps, delta, alpha)
+	}
+}
+
+func TestFixedTimestep(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	w.SetFixedTimestep(0.01, 5)
+
+	steps, delta, alpha := w.timestep.advance(0.025)
+	if steps != 2 || delta != 0.01 || math.Abs(alpha-0.5) > 1e-9 {
+		t.Errorf("expected 2 ticks of 0.01 and alpha 0.5. got %v, %v, %v", steps, delta, alpha)
+	}
+
+	// the leftover time is carried over to the next frame
+	steps, _, alpha = w.timestep.advance(0.006)
+	if steps != 1 || math.Abs(alpha-0.1) > 1e-9 {
+		t.Errorf("expected 1 tick and alpha 0.1. got %v, %v", steps, alpha)
+	}
+}
+
+func TestFixedTimestepCatchUpCap(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	w.SetFixedTimestep(0.01, 3)
+
+	steps, _, alpha := w.timestep.advance(1.005)
+	if steps != 3 {
+		t.Errorf("expected catch-up to be capped to 3 ticks, got %v", steps)
+	}
+	if alpha < 0 || alpha >= 1 {
+		t.Errorf("alpha out of range after dropping time: %v", alpha)
+	}
+
+	// the dropped time should not come back in later frames
+	steps, _, _ = w.timestep.advance(0.01)
+	if steps > 1 {
+		t.Errorf("expected at most 1 tick after dropping time, got %v", steps)
+	}
+}
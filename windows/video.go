@@ -0,0 +1,219 @@
+package windows
+
+import (
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"os"
+	"path/filepath"
+
+	"github.com/krapulacoders/krapulaengine2/graphics"
+)
+
+// VideoFormat is the output format of a video recording
+type VideoFormat int
+
+// Video formats
+const (
+	// VideoPNGSequence writes numbered PNG files into a directory
+	VideoPNGSequence VideoFormat = iota
+	// VideoGIF writes an animated GIF
+	VideoGIF
+)
+
+// number of pixel buffers frames are read back through
+const videoReadBuffers = 3
+
+// number of frames waiting to be encoded before the main loop waits for the encoder
+const videoQueueLength = 60
+
+// GIFs are encoded when the recording stops, so their frames are kept in memory.
+// Recordings are stopped when the frames would take more than this many bytes.
+const maxGIFMemory = 256 << 20
+
+// VideoConfig describes a video recording
+type VideoConfig struct {
+	Format VideoFormat
+	// The directory of a PNG sequence or the file of a GIF
+	Path string
+	// Frames per second of the video. The game runs with a fixed frame time of
+	// 1/FrameRate while recording, regardless of how long frames really take.
+	FrameRate float64
+	// Length of the recording in seconds, 0 records until stopped. GIFs are limited to
+	// about 256 MB of frames, e.g. 2 minutes at 30 fps in 1280x720.
+	Duration float64
+}
+
+// videoRecorder reads back the rendered frames and encodes them in another goroutine
+type videoRecorder struct {
+	config     VideoConfig
+	reader     *graphics.FrameReader
+	frames     chan *image.RGBA
+	done       chan error
+	recorded   int
+	frameLimit int
+}
+
+// StartVideoRecording starts recording the rendered frames of the window
+func (w *Window) StartVideoRecording(config VideoConfig) error {
+	if w.video != nil {
+		return fmt.Errorf("already recording video")
+	}
+	if config.FrameRate <= 0 {
+		return fmt.Errorf("invalid video frame rate %v", config.FrameRate)
+	}
+	if w.window == nil {
+		return fmt.Errorf("can't record video of a window that isn't open")
+	}
+	if config.Format == VideoPNGSequence {
+		if err := os.MkdirAll(config.Path, 0755); err != nil {
+			return err
+		}
+	}
+
+	// the reader belongs to the GL context of this window
+	restore := w.useContext()
+	defer restore()
+	size := w.graphics.GetViewPortSize()
+	width, height := int(size.X()), int(size.Y())
+	v := &videoRecorder{
+		config:     config,
+		reader:     graphics.NewFrameReader(width, height, videoReadBuffers),
+		frames:     make(chan *image.RGBA, videoQueueLength),
+		done:       make(chan error, 1),
+		frameLimit: int(config.Duration*config.FrameRate + 0.5),
+	}
+	if config.Format == VideoGIF && width > 0 && height > 0 {
+		limit := maxGIFMemory / (width * height)
+		if limit < 1 {
+			limit = 1
+		}
+		if v.frameLimit == 0 || v.frameLimit > limit {
+			v.frameLimit = limit
+		}
+	}
+	go encodeVideo(config, v.frames, v.done)
+	w.video = v
+	return nil
+}
+
+// StopVideoRecording stops the recording and waits for the encoding to finish
+func (w *Window) StopVideoRecording() error {
+	v := w.video
+	if v == nil {
+		return nil
+	}
+	w.video = nil
+	for _, img := range v.reader.Flush() {
+		v.send(img)
+	}
+	v.reader.Delete()
+	close(v.frames)
+	return <-v.done
+}
+
+// IsRecordingVideo returns true while a video is recorded
+func (w *Window) IsRecordingVideo() bool {
+	return w.video != nil
+}
+
+// videoFrameTime returns the frame time to use instead of the real one while recording
+func (w *Window) videoFrameTime(timedelta float64) float64 {
+	if w.video == nil {
+		return timedelta
+	}
+	return 1 / w.video.config.FrameRate
+}
+
+// captureVideoFrame queues the rendered frame for recording. It must be called before the buffers are swapped.
+// If the framebuffer has been resized, a PNG sequence continues in the new size and a GIF recording stops,
+// since all frames of a GIF must fit its first one.
+func (w *Window) captureVideoFrame() {
+	v := w.video
+	if v == nil {
+		return
+	}
+	size := w.graphics.GetViewPortSize()
+	width, height := int(size.X()), int(size.Y())
+	if readerWidth, readerHeight := v.reader.Size(); width != readerWidth || height != readerHeight {
+		if v.config.Format == VideoGIF {
+			if err := w.StopVideoRecording(); err != nil {
+				fmt.Println("Can't save video:", err)
+			}
+			return
+		}
+		for _, img := range v.reader.Flush() {
+			v.send(img)
+		}
+		v.reader.Delete()
+		v.reader = graphics.NewFrameReader(width, height, videoReadBuffers)
+	}
+	if img, ok := v.reader.Queue(); ok {
+		v.send(img)
+	}
+	v.recorded++
+	if v.frameLimit > 0 && v.recorded >= v.frameLimit {
+		if err := w.StopVideoRecording(); err != nil {
+			fmt.Println("Can't save video:", err)
+		}
+	}
+}
+
+// send queues a frame for encoding. If the encoder has fallen too far behind, the main loop
+// waits for it. The game runs on the virtual frame time while recording, so waiting doesn't
+// show in the video, while dropping frames would.
+func (v *videoRecorder) send(img *image.RGBA) {
+	v.frames <- img
+}
+
+// encodeVideo encodes the frames until the channel is closed and then sends the result to done
+func encodeVideo(config VideoConfig, frames <-chan *image.RGBA, done chan<- error) {
+	var err error
+	anim := new(gif.GIF)
+	delay := int(100/config.FrameRate + 0.5)
+	n := 0
+	for img := range frames {
+		if err != nil {
+			// keep draining so the main loop doesn't block
+			continue
+		}
+		switch config.Format {
+		case VideoPNGSequence:
+			err = writePNG(filepath.Join(config.Path, fmt.Sprintf("frame-%05d.png", n)), img)
+		case VideoGIF:
+			paletted := image.NewPaletted(img.Bounds(), palette.Plan9)
+			draw.FloydSteinberg.Draw(paletted, img.Bounds(), img, image.ZP)
+			anim.Image = append(anim.Image, paletted)
+			anim.Delay = append(anim.Delay, delay)
+		}
+		n++
+	}
+	if err == nil && config.Format == VideoGIF {
+		err = writeGIF(config.Path, anim)
+	}
+	done <- err
+}
+
+func writeGIF(file string, anim *gif.GIF) error {
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := gif.EncodeAll(out, anim); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// StartVideoRecording starts recording the main window
+func StartVideoRecording(config VideoConfig) error {
+	return MainWindow.StartVideoRecording(config)
+}
+
+// StopVideoRecording stops recording the main window
+func StopVideoRecording() error {
+	return MainWindow.StopVideoRecording()
+}
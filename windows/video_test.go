@@ -0,0 +1,150 @@
+package windows
+
+import (
+	"image"
+	"image/gif"
+	_ "image/png"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// runHeadlessRecording runs the main loop headless for the given number of frames while recording video
+func runHeadlessRecording(t *testing.T, config VideoConfig, frames int) *headlessTestScene {
+	return runHeadlessRecordingWithInput(t, config, frames, nil)
+}
+
+// runHeadlessRecordingWithInput is runHeadlessRecording with a script for the input after the first frame
+func runHeadlessRecordingWithInput(t *testing.T, config VideoConfig, frames int, script InputScript) *headlessTestScene {
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(16, 8, "headless")
+	defer func() { MainWindow = main }()
+
+	scene := new(headlessTestScene)
+	MainWindow.AddScene("test", scene)
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		switch frame {
+		case 0:
+			if err := MainWindow.StartVideoRecording(config); err != nil {
+				t.Fatal(err)
+			}
+		case frames:
+			input.Close()
+		default:
+			if script != nil {
+				script(frame, input)
+			}
+		}
+	})
+	MainLoop()
+	return scene
+}
+
+func TestVideoGIF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "video")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "out.gif")
+
+	scene := runHeadlessRecording(t, VideoConfig{Format: VideoGIF, Path: file, FrameRate: 10, Duration: 0.5}, 8)
+
+	// the game runs at the virtual frame rate while recording
+	for i, timedelta := range scene.timedeltas[:5] {
+		if math.Abs(timedelta-0.1) > 1e-9 {
+			t.Errorf("frame %v: expected the virtual frame time, got %v", i, timedelta)
+		}
+	}
+	if math.Abs(scene.timedeltas[5]-DefaultHeadlessFrameTime) > 1e-9 {
+		t.Errorf("expected the real frame time after the recording, got %v", scene.timedeltas[5])
+	}
+
+	in, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	anim, err := gif.DecodeAll(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 5 || anim.Delay[0] != 10 {
+		t.Errorf("expected 5 frames of 10/100 s, got %v frames with delays %v", len(anim.Image), anim.Delay)
+	}
+	if anim.Config.Width != 16 || anim.Config.Height != 8 {
+		t.Errorf("expected the window size, got %vx%v", anim.Config.Width, anim.Config.Height)
+	}
+}
+
+func TestVideoPNGSequence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "video")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// recording until the window closes
+	runHeadlessRecording(t, VideoConfig{Format: VideoPNGSequence, Path: dir, FrameRate: 30}, 4)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "frame-*.png"))
+	if len(files) != 4 || filepath.Base(files[3]) != "frame-00003.png" {
+		t.Errorf("expected 4 numbered frames, got %v", files)
+	}
+}
+
+func TestVideoResize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "video")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	resize := func(frame int, input *HeadlessInput) {
+		if frame == 2 {
+			input.Resize(8, 4)
+		}
+	}
+	runHeadlessRecordingWithInput(t, VideoConfig{Format: VideoPNGSequence, Path: dir, FrameRate: 30}, 4, resize)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "frame-*.png"))
+	if len(files) != 4 {
+		t.Fatalf("the recording should continue after resizing, got %v", files)
+	}
+	for i, expected := range []image.Point{{16, 8}, {16, 8}, {8, 4}, {8, 4}} {
+		in, err := os.Open(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, _, err := image.DecodeConfig(in)
+		in.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if config.Width != expected.X || config.Height != expected.Y {
+			t.Errorf("frame %v: expected %v, got %vx%v", i, expected, config.Width, config.Height)
+		}
+	}
+
+	// a GIF can't change size, so the recording stops
+	file := filepath.Join(dir, "out.gif")
+	runHeadlessRecordingWithInput(t, VideoConfig{Format: VideoGIF, Path: file, FrameRate: 10}, 4, resize)
+	in, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer in.Close()
+	anim, err := gif.DecodeAll(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) != 2 {
+		t.Errorf("expected the frames before resizing, got %v", len(anim.Image))
+	}
+}
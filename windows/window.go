@@ -1,27 +1,19 @@
 package windows
 
 import (
+	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/go-gl/glfw/v3.2/glfw"
 	"github.com/krapulacoders/krapulaengine2/graphics"
 )
 
-// MainWindow is _the_ window
-var MainWindow = newWindow(800, 600)
+// MainWindow is the first window. The main loop ends when it is closed.
+var MainWindow = NewWindowWithConfig(DefaultConfig())
 
-// Inits glfw
-func initGLFW() {
-
-	if err := glfw.Init(); err != nil {
-		panic("Can't init glfw: " + err.Error())
-	}
-
-	glfw.WindowHint(glfw.Resizable, glfw.True)
-	glfw.WindowHint(glfw.ContextVersionMajor, 3)
-	glfw.WindowHint(glfw.ContextVersionMinor, 3)
-	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
-}
+// the windows that have been opened, in the order they were opened
+var openWindows []*Window
 
 // WindowAction is something the window should do
 type WindowAction int
@@ -30,101 +22,278 @@ type WindowAction int
 const (
 	WindowActionNone WindowAction = iota
 	WindowActionExit              = iota
+	// WindowActionConsumeInput stops the input of this frame from reaching the scenes below
+	WindowActionConsumeInput = iota
 )
 
 // Window is an actual window in the OS.
 type Window struct {
-	window *glfw.Window
+	window PlatformWindow
+
+	// the graphics state of the window's GL context
+	graphics *graphics.Context
 
 	// general window info
-	width, height int
+	config Config
 
-	// Loaded scenes. Only one can be active at any time
-	scenes       map[string]Scene
-	currentScene string
+	// Loaded scenes. Only the scenes at the top of the stack are active
+	scenes     map[string]Scene
+	sceneStack []string
+	// ids of the scenes in the order they were added, so they are inited in the same order every run
+	sceneOrder []string
 
 	// A set of overlays that can be rendered on top of the actual game scene
 	overlays map[string]Scene
 	// many can be active at the same time, keep track of which ones are active
 	activeOverlays map[string]bool
+	// the overlays ordered by z, bottom first
+	overlayOrder []string
+	overlayZ     map[string]int
 
 	// set to true when the main loop should quit
 	quit bool
-
-	// internal input channels
-	keyInput       chan KeyboardInputEvent
-	mouseInput     chan MouseInputEvent
+	// set to true once the main loop has inited the scenes
+	running bool
+
+	// window state from the window events
+	windowEvents                 []WindowEvent
+	focused, iconified           bool
+	contentScaleX, contentScaleY float32
+	pauseOnFocusLoss             bool
+	focusPausedScenes            []Scene
+
+	// told about scene state changes
+	sceneObserver SceneObserver
+
+	// how frame times are turned into ticks
+	timestep timestep
+
+	// frame pacing and timings
+	targetFPS  float64
+	frameStats *frameStats
+
+	// screenshots
+	screenshotKey       glfw.Key
+	screenshotMods      glfw.ModifierKey
+	screenshotDir       string
+	screenshotRequested bool
+	lastScreenshot      string
+
+	// video recording, nil when not recording
+	video *videoRecorder
+
+	// background scene loading
+	loadTasks  chan func()
+	loading    *sceneLoad
+	loadError  error
+	loadBudget time.Duration
+
+	// named actions bound to the input
+	actions *ActionMap
+
+	// input recording and replay
+	recorder        *InputRecorder
+	replayer        *InputReplayer
+	exitAfterReplay bool
+
+	// input queued by the callbacks until the next frame. The callbacks run on the main thread
+	// during PollEvents, so appending can't race with the frame and never blocks.
+	keyQueue       []KeyboardInputEvent
+	mouseQueue     []MouseInputEvent
+	charQueue      []CharInputEvent
+	scrollQueue    []ScrollInputEvent
+	cursorQueue    []CursorEnterEvent
+	dropQueue      []DropEvent
 	mouseX, mouseY float32
 	keyEvents      []KeyboardInputEvent
 	mouseEvents    []MouseInputEvent
+	charEvents     []CharInputEvent
+	scrollEvents   []ScrollInputEvent
+	cursorEvents   []CursorEnterEvent
+	dropEvents     []DropEvent
 	keyStates      []bool
+	gamepads       *gamepads
+}
+
+// NewWindow allocates a new window with the default config and the given size and title.
+// Call Open to create it, the main window is opened by MainLoop.
+func NewWindow(width, height int, title string) *Window {
+	config := DefaultConfig()
+	config.Width, config.Height, config.Title = width, height, title
+	return NewWindowWithConfig(config)
 }
 
-// Allocates a new Window
-func newWindow(width, height int) *Window {
+// NewWindowWithConfig allocates a new window. Call Open to create it.
+func NewWindowWithConfig(config Config) *Window {
 	w := new(Window)
+	w.config = config
 	w.scenes = make(map[string]Scene)
 	w.overlays = make(map[string]Scene)
 	w.activeOverlays = make(map[string]bool)
-	w.currentScene = ""
-	w.width = width
-	w.height = height
-	w.keyInput = make(chan KeyboardInputEvent, 100)
-	w.mouseInput = make(chan MouseInputEvent, 100)
+	w.overlayZ = make(map[string]int)
+	w.sceneStack = make([]string, 0, 10)
+	w.focused = true
+	w.contentScaleX, w.contentScaleY = 1, 1
 	w.keyEvents = make([]KeyboardInputEvent, 0, 100)
 	w.mouseEvents = make([]MouseInputEvent, 0, 100)
+	w.charEvents = make([]CharInputEvent, 0, 100)
+	w.scrollEvents = make([]ScrollInputEvent, 0, 100)
+	w.cursorEvents = make([]CursorEnterEvent, 0, 10)
+	w.dropEvents = make([]DropEvent, 0, 10)
 	w.keyStates = make([]bool, glfw.KeyLast+1)
+	w.gamepads = newGamepads(nil)
+	w.actions = NewActionMap()
+	w.frameStats = newFrameStats()
+	w.screenshotKey = glfw.KeyUnknown
+	w.loadTasks = make(chan func(), 64)
+	w.loadBudget = DefaultLoadBudget
 	return w
 }
 
-// Init Creates the window and initializes the graphics thread
+// Init Creates the main window and initializes the graphics thread
 func Init() {
-	initGLFW()
+	MainWindow.Open()
+}
+
+// Open creates the OS window and its GL context using the platform. The context shares
+// textures and shaders with the windows opened before it.
+func (w *Window) Open() {
+	if w.window != nil {
+		return
+	}
+	initPlatform()
+
+	var share PlatformWindow
+	if len(openWindows) > 0 {
+		share = openWindows[0].window
+	}
+	w.window = platform.CreateWindow(w, share)
+	w.graphics = w.window.Graphics()
+	graphics.MakeContextCurrent(w.graphics)
+
+	w.quit = false
+	openWindows = append(openWindows, w)
+}
+
+// Close exits the scenes of the window and destroys it
+func (w *Window) Close() {
+	if w.window == nil {
+		return
+	}
+	w.makeCurrent()
+	if err := w.StopVideoRecording(); err != nil {
+		fmt.Println("Can't save video:", err)
+	}
+	for _, scene := range w.orderedScenes() {
+		if !scene.IsTerminated() {
+			logSceneError(scene.Exit())
+		}
+	}
+	for _, scene := range w.orderedOverlays() {
+		if !scene.IsTerminated() {
+			logSceneError(scene.Exit())
+		}
+	}
+	w.running = false
+	w.graphics.Deinit()
+	if len(openWindows) == 1 && openWindows[0] == w {
+		graphics.ReleaseSharedResources()
+	}
+	w.window.Destroy()
+	w.window = nil
 
-	window, err := glfw.CreateWindow(MainWindow.width, MainWindow.height, "Testing", nil, nil)
-	if err != nil {
-		panic("Can't create window")
+	for i, open := range openWindows {
+		if open == w {
+			openWindows = append(openWindows[:i], openWindows[i+1:]...)
+			break
+		}
 	}
+}
 
-	window.MakeContextCurrent()
+// IsOpen returns true if the window has been opened and not closed
+func (w *Window) IsOpen() bool {
+	return w.window != nil
+}
 
-	MainWindow.window = window
-	graphics.InitMasterLoop()
-	window.SetKeyCallback(keyEventHandler())
-	window.SetCursorPosCallback(cursorPosHandler())
-	window.SetMouseButtonCallback(mouseButtonEventHandler())
-	window.SetFramebufferSizeCallback(func(window *glfw.Window, w, h int) {
-		graphics.SetViewPortSize(w, h)
-	})
-	// also set initial viewpost size...
-	w, h := window.GetFramebufferSize()
-	graphics.SetViewPortSize(w, h)
+// makeCurrent makes the GL context and the graphics state of the window current
+func (w *Window) makeCurrent() {
+	w.window.MakeContextCurrent()
+	graphics.MakeContextCurrent(w.graphics)
+}
 
+// useContext makes the window current and returns a function that makes the
+// window that was current before current again
+func (w *Window) useContext() (restore func()) {
+	previous := currentWindow()
+	w.makeCurrent()
+	return func() {
+		if previous != nil && previous != w && previous.IsOpen() {
+			previous.makeCurrent()
+		}
+	}
 }
-func keyEventHandler() glfw.KeyCallback {
-	return func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-		MainWindow.keyInput <- KeyboardInputEvent{key, scancode, action, mods}
+
+// currentWindow returns the open window whose graphics context is current, or nil
+func currentWindow() *Window {
+	current := graphics.CurrentContext()
+	for _, w := range openWindows {
+		if w.graphics == current {
+			return w
+		}
 	}
+	return nil
 }
 
-func mouseButtonEventHandler() glfw.MouseButtonCallback {
-	return func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
-		MainWindow.mouseInput <- MouseInputEvent{button, action, mod, MainWindow.mouseX, MainWindow.mouseY}
+func (w *Window) keyEventHandler() glfw.KeyCallback {
+	return func(window *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		w.keyQueue = append(w.keyQueue, KeyboardInputEvent{key, scancode, action, mods})
 	}
 }
 
-func cursorPosHandler() glfw.CursorPosCallback {
-	return func(w *glfw.Window, xpos float64, ypos float64) {
-		MainWindow.mouseX = float32(xpos)
-		MainWindow.mouseY = float32(ypos)
+func (w *Window) mouseButtonEventHandler() glfw.MouseButtonCallback {
+	return func(window *glfw.Window, button glfw.MouseButton, action glfw.Action, mod glfw.ModifierKey) {
+		w.mouseQueue = append(w.mouseQueue, MouseInputEvent{button, action, mod, w.mouseX, w.mouseY})
 	}
 }
 
-// MainLoop initializes its scenes and then enters the loop that runs the game.
-// It directs input events to the current scene and any overlays and renders them.
-// When the loop ends, the scenes are terminated
+func (w *Window) charEventHandler() glfw.CharCallback {
+	return func(window *glfw.Window, char rune) {
+		w.charQueue = append(w.charQueue, CharInputEvent{char})
+	}
+}
+
+func (w *Window) scrollEventHandler() glfw.ScrollCallback {
+	return func(window *glfw.Window, xoff float64, yoff float64) {
+		w.scrollQueue = append(w.scrollQueue, ScrollInputEvent{xoff, yoff, w.mouseX, w.mouseY})
+	}
+}
+
+func (w *Window) cursorEnterHandler() glfw.CursorEnterCallback {
+	return func(window *glfw.Window, entered bool) {
+		w.cursorQueue = append(w.cursorQueue, CursorEnterEvent{entered})
+	}
+}
+
+func (w *Window) dropHandler() glfw.DropCallback {
+	return func(window *glfw.Window, names []string) {
+		w.dropQueue = append(w.dropQueue, DropEvent{names, w.mouseX, w.mouseY})
+	}
+}
+
+func (w *Window) cursorPosHandler() glfw.CursorPosCallback {
+	return func(window *glfw.Window, xpos float64, ypos float64) {
+		w.mouseX = float32(xpos)
+		w.mouseY = float32(ypos)
+	}
+}
+
+// MainLoop initializes the scenes of all open windows and then enters the loop that runs the game.
+// Each window directs input events to its current scenes and overlays and renders them.
+// A window closes when it quits, and the loop ends when the main window quits.
+// When the loop ends, all windows are closed and their scenes are terminated.
+// See SetDeterministic for running the loop on a virtual clock.
 func MainLoop() {
-	// GLFW event handling must run on the main OS thread
+	// GLFW event handling and GL calls must run on the main OS thread
 	runtime.LockOSThread()
 
 	if MainWindow.window == nil {
@@ -132,167 +301,420 @@ func MainLoop() {
 	}
 
 	defer func() {
-		// Terminate all Scenes at exit
-		for _, scene := range MainWindow.scenes {
-			scene.Exit()
+		// Close the other windows before the main window that owns the shared objects
+		for len(openWindows) > 0 {
+			openWindows[len(openWindows)-1].Close()
 		}
-		for _, scene := range MainWindow.overlays {
-			scene.Exit()
-		}
-		MainWindow.window = nil
-		graphics.DeinitMasterLoop()
-		glfw.Terminate()
+		terminatePlatform()
 	}()
 
-	initScenes()
-
 	MainWindow.quit = false
-	oldTime := glfw.GetTime()
+	if deterministic != nil {
+		deterministic.start()
+	}
+	oldTime := LoopTime()
 	for !MainWindow.quit {
-		MainWindow.window.MakeContextCurrent()
-		newTime := glfw.GetTime()
+		if deterministic != nil {
+			deterministic.nextFrame()
+		}
+		newTime := LoopTime()
 		timedelta := newTime - oldTime
 		oldTime = newTime
 
-		if MainWindow.window.ShouldClose() {
-			MainWindow.quit = true
+		platform.PollEvents()
+
+		// windows may be opened or closed during the frame
+		for _, w := range append([]*Window(nil), openWindows...) {
+			w.frame(timedelta)
+			if w.quit && w != MainWindow {
+				w.Close()
+			}
 		}
-		processInput()
-		if MainWindow.quit {
-			break
+
+		if deterministic != nil {
+			if deterministic.done() {
+				MainWindow.quit = true
+			}
+		} else if MainWindow.targetFPS > 0 {
+			frameEnd := newTime + 1/MainWindow.targetFPS
+			if now := platform.Time(); now < frameEnd {
+				platform.Wait(frameEnd - now)
+			}
 		}
-		tick(timedelta)
+	}
+}
 
-		graphics.Render()
-		MainWindow.window.SwapBuffers()
+// frame runs one frame of the window: input, ticks and rendering
+func (w *Window) frame(timedelta float64) {
+	w.makeCurrent()
+	if !w.running {
+		w.initScenes()
+	}
+
+	w.frameStats.addFrame(timedelta)
+	stats := &w.frameStats.stats
+	start := platform.Time()
 
+	if w.window.ShouldClose() {
+		w.quit = true
 	}
+	timedelta = w.processInput(w.videoFrameTime(timedelta))
+	if w.quit {
+		return
+	}
+	inputDone := platform.Time()
+	stats.InputTime = inputDone - start
+
+	w.updateLoading()
+	w.advance(timedelta)
+	tickDone := platform.Time()
+	stats.TickTime = tickDone - inputDone
+
+	w.render()
+	w.saveRequestedScreenshot()
+	w.captureVideoFrame()
+	renderDone := platform.Time()
+	stats.RenderTime = renderDone - tickDone
+
+	w.window.SwapBuffers()
+	stats.SwapTime = platform.Time() - renderDone
 }
 
-// Init() scenes, then Run() them
-func initScenes() {
-	// Init all scenes
-	for _, scene := range MainWindow.scenes {
-		if !scene.IsInited() {
+// orderedScenes returns the scenes in the order they were added
+func (w *Window) orderedScenes() []Scene {
+	scenes := make([]Scene, len(w.sceneOrder))
+	for i, id := range w.sceneOrder {
+		scenes[i] = w.scenes[id]
+	}
+	return scenes
+}
+
+// orderedOverlays returns the overlays bottom first
+func (w *Window) orderedOverlays() []Scene {
+	overlays := make([]Scene, len(w.overlayOrder))
+	for i, id := range w.overlayOrder {
+		overlays[i] = w.overlays[id]
+	}
+	return overlays
+}
+
+// Init() scenes, then Run() the active ones
+func (w *Window) initScenes() {
+	// Init all scenes. Async scenes are loaded when they are needed.
+	for _, scene := range w.orderedScenes() {
+		if _, async := scene.(AsyncScene); !async && !scene.IsInited() {
 			scene.Init()
 		}
 	}
-	for _, scene := range MainWindow.overlays {
+	for _, scene := range w.orderedOverlays() {
 		if !scene.IsInited() {
 			scene.Init()
 		}
 	}
+	for _, scene := range w.orderedScenes() {
+		scene.SetFocused(w.focused)
+	}
+	for _, scene := range w.orderedOverlays() {
+		scene.SetFocused(w.focused)
+	}
 
-	// Mark all scenes as running.
+	// Mark the active scenes as running. Scenes further down the stack are
+	// started when they are uncovered.
 	// This is done after Init because scenes may depend on each other being inited.
-	for _, scene := range MainWindow.scenes {
-		scene.Run()
+	for _, scene := range w.activeScenes() {
+		if !scene.IsInited() && w.initScene(scene) != nil {
+			continue
+		}
+		if !scene.IsRunning() {
+			logSceneError(runScene(scene))
+		}
 	}
-	for _, scene := range MainWindow.overlays {
-		scene.Run()
+	for _, scene := range w.visibleOverlays() {
+		if !scene.IsRunning() {
+			logSceneError(runScene(scene))
+		}
 	}
+	w.running = true
+}
+
+// Exit quits the window. Closing the main window ends the main loop.
+func (w *Window) Exit() {
+	w.quit = true
 }
 
-// Exit the mainloop
+// Exit the mainloop. The windows and their resources are released when the loop ends.
 func Exit() {
 	MainWindow.quit = true
-	graphics.DeinitMasterLoop()
 }
 
-// AddScene adds a scene
+// AddScene adds a scene. The first scene added is pushed onto the scene stack.
 func AddScene(id string, scene Scene) {
-	if _, exists := MainWindow.scenes[id]; exists {
+	MainWindow.AddScene(id, scene)
+}
+
+// AddScene adds a scene. The first scene added is pushed onto the scene stack.
+func (w *Window) AddScene(id string, scene Scene) {
+	if _, exists := w.scenes[id]; exists {
 		panic("Tried adding scene twice to window")
 	}
-	MainWindow.scenes[id] = scene
-	if MainWindow.currentScene == "" {
-		SetCurrentScene(id)
+	w.scenes[id] = scene
+	w.sceneOrder = append(w.sceneOrder, id)
+	w.observeScene(id, scene)
+	if len(w.sceneStack) == 0 {
+		w.PushScene(id)
 	}
 }
 
-// SetCurrentScene sets the current scene
+// SetCurrentScene replaces the top of the scene stack with the given scene
 func SetCurrentScene(id string) {
-	MainWindow.currentScene = id
+	MainWindow.ReplaceScene(id)
 }
 
-// AddOverlay  adds an overlay
-func AddOverlay(id string, scene Scene) {
-	if _, exists := MainWindow.overlays[id]; exists {
-		panic("Tried adding overlay twice to window")
-	}
-	MainWindow.overlays[id] = scene
+// processInput drains the input polled for this frame, records or replays it and sends it to the scenes.
+// Returns the timedelta to use for the frame, which comes from the recording when replaying.
+func (w *Window) processInput(timedelta float64) float64 {
+
+	// process input
+	w.handleWindowEvents()
+	w.gamepads.poll()
+	w.drainInput()
+
+	timedelta = w.recordOrReplay(timedelta)
+	w.checkScreenshotKey()
+	w.dispatchInput()
+	return timedelta
 }
 
-func processInput() {
+// drainInput moves the events queued by the callbacks to this frame's events
+func (w *Window) drainInput() {
+	w.keyEvents = append(w.keyEvents[:0], w.keyQueue...)
+	w.mouseEvents = append(w.mouseEvents[:0], w.mouseQueue...)
+	w.charEvents = append(w.charEvents[:0], w.charQueue...)
+	w.scrollEvents = append(w.scrollEvents[:0], w.scrollQueue...)
+	w.cursorEvents = append(w.cursorEvents[:0], w.cursorQueue...)
+	w.dropEvents = append(w.dropEvents[:0], w.dropQueue...)
+	w.keyQueue = w.keyQueue[:0]
+	w.mouseQueue = w.mouseQueue[:0]
+	w.charQueue = w.charQueue[:0]
+	w.scrollQueue = w.scrollQueue[:0]
+	w.cursorQueue = w.cursorQueue[:0]
+	w.dropQueue = w.dropQueue[:0]
+}
 
-	// process input
-	glfw.PollEvents()
-	// clear the slice
-	MainWindow.keyEvents = MainWindow.keyEvents[:0]
-	// key events
-	hasInput := true
-	for hasInput {
-		select {
-		case keyEvent := <-MainWindow.keyInput:
-			MainWindow.keyEvents = append(MainWindow.keyEvents, keyEvent)
-			if keyEvent.Action == glfw.Release {
-				MainWindow.keyStates[keyEvent.Key] = false
-			} else {
-				MainWindow.keyStates[keyEvent.Key] = true
-			}
-		default:
-			hasInput = false
+// updateInputStates applies the input events of this frame that no scene consumed to the key states
+// and actions. Releases are always applied, so that keys don't stay held when a scene consumes them.
+// The actions were updated with all events before dispatching, so they are put back to saved
+// and updated again if any event was consumed.
+func (w *Window) updateInputStates(batch *InputBatch, saved actionMapState) {
+	keyEvents := make([]KeyboardInputEvent, 0, len(w.keyEvents))
+	left := remainingEvents(len(w.keyEvents), batch.keyIndices)
+	for i, event := range w.keyEvents {
+		if left[i] || event.Action == glfw.Release {
+			keyEvents = append(keyEvents, event)
+		}
+	}
+	mouseEvents := make([]MouseInputEvent, 0, len(w.mouseEvents))
+	left = remainingEvents(len(w.mouseEvents), batch.mouseIndices)
+	for i, event := range w.mouseEvents {
+		if left[i] || event.Action == glfw.Release {
+			mouseEvents = append(mouseEvents, event)
+		}
+	}
+	buttonEvents := make([]GamepadButtonEvent, 0, len(w.gamepads.buttonEvents))
+	left = remainingEvents(len(w.gamepads.buttonEvents), batch.gamepadButtonIndices)
+	for i, event := range w.gamepads.buttonEvents {
+		if left[i] || event.Action == glfw.Release {
+			buttonEvents = append(buttonEvents, event)
 		}
 	}
 
-	// mouse events
-	// clear the slice
-	MainWindow.mouseEvents = MainWindow.mouseEvents[:0]
-	hasInput = true
-	for hasInput {
-		select {
-		case mouseEvent := <-MainWindow.mouseInput:
-			MainWindow.mouseEvents = append(MainWindow.mouseEvents, mouseEvent)
+	if len(keyEvents) != len(w.keyEvents) || len(mouseEvents) != len(w.mouseEvents) ||
+		len(buttonEvents) != len(w.gamepads.buttonEvents) {
+		w.actions.restore(saved)
+		w.actions.update(keyEvents, mouseEvents)
+		w.actions.updateGamepads(buttonEvents, w.gamepads.states)
+	}
+	for _, keyEvent := range keyEvents {
+		if keyEvent.Key < 0 || int(keyEvent.Key) >= len(w.keyStates) {
+			continue
+		}
+		if keyEvent.Action == glfw.Release {
+			w.keyStates[keyEvent.Key] = false
+		} else {
+			w.keyStates[keyEvent.Key] = true
+		}
+	}
+}
 
-		default:
-			hasInput = false
+// remainingEvents returns which of n events are listed in indices
+func remainingEvents(n int, indices []int) []bool {
+	left := make([]bool, n)
+	for _, i := range indices {
+		if i < n {
+			left[i] = true
 		}
 	}
+	return left
+}
+
+// dispatchInput sends the input of this frame to the visible overlays and the active scenes, top-down,
+// and then updates the key states. The actions are updated first, so scenes can check them in
+// HandleInput. Events consumed by a scene don't reach the scenes below it, and are taken back
+// out of the actions and key states afterwards.
+func (w *Window) dispatchInput() {
+	saved := w.actions.save()
+	w.actions.update(w.keyEvents, w.mouseEvents)
+	w.actions.updateGamepads(w.gamepads.buttonEvents, w.gamepads.states)
+	batch := w.inputBatch()
 
-	// Send inputs to scenes
 	// Overlays get input first.
+	for _, overlay := range w.visibleOverlays() {
+		w.sendInput(overlay, batch)
+	}
 
-	for _, overlay := range MainWindow.overlays {
-		if overlay.AcceptsInput() {
-			action := overlay.HandleInput(MainWindow.keyEvents, MainWindow.mouseEvents)
-			switch action {
-			case WindowActionExit:
-				Exit()
-			}
-		}
+	// Scenes may push or pop scenes while handling input,
+	// so iterate over the scenes that were active when the frame started.
+	for _, scene := range w.activeScenes() {
+		w.sendInput(scene, batch)
 	}
+	w.updateInputStates(batch, saved)
+}
 
-	for _, scene := range MainWindow.scenes {
-		if scene.AcceptsInput() {
-			action := scene.HandleInput(MainWindow.keyEvents, MainWindow.mouseEvents)
-			switch action {
-			case WindowActionExit:
-				Exit()
+// sendInput sends the input left in the batch to one scene
+func (w *Window) sendInput(scene Scene, batch *InputBatch) {
+	if !scene.AcceptsInput() {
+		return
+	}
+	var action WindowAction
+	if handler, ok := scene.(BatchInputHandler); ok {
+		action = handler.HandleInputBatch(batch)
+	} else {
+		action = scene.HandleInput(batch.KeyEvents, batch.MouseEvents)
+		if handler, ok := scene.(GamepadInputHandler); ok {
+			gamepadAction := handler.HandleGamepadInput(batch.GamepadConnectionEvents, batch.GamepadButtonEvents)
+			if gamepadAction == WindowActionConsumeInput {
+				action = gamepadAction
+			} else {
+				w.handleWindowAction(gamepadAction)
 			}
 		}
 	}
+	if action == WindowActionConsumeInput {
+		batch.ConsumeAll()
+	}
+	w.handleWindowAction(action)
+	batch.removeConsumed()
 }
 
-func tick(timedelta float64) {
-	for _, scene := range MainWindow.scenes {
-		scene.Tick(timedelta, MainWindow.keyStates)
+// inputBatch returns all input of this frame
+func (w *Window) inputBatch() *InputBatch {
+	return &InputBatch{
+		KeyEvents:    w.keyEvents,
+		MouseEvents:  w.mouseEvents,
+		CharEvents:   w.charEvents,
+		ScrollEvents: w.scrollEvents,
+		CursorEvents: w.cursorEvents,
+		DropEvents:   w.dropEvents,
+
+		GamepadConnectionEvents: w.gamepads.connectionEvents,
+		GamepadButtonEvents:     w.gamepads.buttonEvents,
+
+		CursorX: w.mouseX,
+		CursorY: w.mouseY,
+
+		keyIndices:           eventIndices(len(w.keyEvents)),
+		mouseIndices:         eventIndices(len(w.mouseEvents)),
+		gamepadButtonIndices: eventIndices(len(w.gamepads.buttonEvents)),
 	}
-	for _, overlay := range MainWindow.overlays {
-		overlay.Tick(timedelta, MainWindow.keyStates)
+}
+
+// eventIndices returns the indices 0 -> n-1
+func eventIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
 	}
+	return indices
 }
 
-// GetSize returns the window size
+func (w *Window) handleWindowAction(action WindowAction) {
+	switch action {
+	case WindowActionExit:
+		w.quit = true
+	}
+}
+
+// advance ticks the scenes according to the timestep mode and
+// passes the interpolation alpha on to rendering
+func (w *Window) advance(timedelta float64) {
+	steps, tickdelta, alpha := w.timestep.advance(timedelta)
+	for i := 0; i < steps; i++ {
+		w.tick(tickdelta)
+	}
+	graphics.SetInterpolationAlpha(float32(alpha))
+}
+
+// render draws the active scenes bottom-up, then the render groups and
+// then the visible overlays bottom-up
+func (w *Window) render() {
+	if graphics.IsHeadless() {
+		return
+	}
+	graphics.BeginFrame()
+	w.renderScenes()
+	graphics.RenderGroups()
+	graphics.ApplyPostProcessing()
+	w.renderOverlays()
+}
+
+// renderScenes renders the active scenes, bottom first
+func (w *Window) renderScenes() {
+	scenes := w.activeScenes()
+	for i := len(scenes) - 1; i >= 0; i-- {
+		scenes[i].Render()
+	}
+}
+
+// renderOverlays renders the visible overlays, bottom first
+func (w *Window) renderOverlays() {
+	overlays := w.visibleOverlays()
+	for i := len(overlays) - 1; i >= 0; i-- {
+		overlays[i].Render()
+	}
+}
+
+func (w *Window) tick(timedelta float64) {
+	for _, scene := range w.activeScenes() {
+		w.tickScene(scene, timedelta)
+	}
+	for _, overlay := range w.visibleOverlays() {
+		w.tickScene(overlay, timedelta)
+	}
+	w.actions.endTick()
+}
+
+func (w *Window) tickScene(scene Scene, timedelta float64) {
+	if scene.IsPaused() {
+		return
+	}
+	if scheduled, ok := scene.(ScheduledScene); ok {
+		if err := scheduled.Scheduler().Update(timedelta); err != nil {
+			fmt.Println("Scheduler error:", err)
+		}
+	}
+	if ticker, ok := scene.(GamepadTicker); ok {
+		ticker.TickWithGamepads(timedelta, w.keyStates, w.gamepads.states)
+	} else {
+		scene.Tick(timedelta, w.keyStates)
+	}
+}
+
+// GetSize returns the size of the main window
 func GetSize() (int, int) {
-	return MainWindow.window.GetSize()
+	return MainWindow.GetSize()
+}
+
+// GetSize returns the window size
+func (w *Window) GetSize() (int, int) {
+	return w.window.GetSize()
 }
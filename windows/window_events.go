@@ -0,0 +1,137 @@
+package windows
+
+// WindowEventType is the kind of a WindowEvent
+type WindowEventType int
+
+// Window event types
+const (
+	WindowFocused WindowEventType = iota
+	WindowUnfocused
+	WindowIconified
+	WindowRestored
+	// The window size changed. Width and Height are the new size.
+	WindowResized
+	// The ratio of framebuffer pixels to window coordinates changed, e.g. when moving to a HiDPI monitor.
+	// ScaleX and ScaleY are the new scale.
+	WindowContentScaleChanged
+)
+
+// WindowEvent is a change of the window itself
+type WindowEvent struct {
+	Type           WindowEventType
+	Width, Height  int
+	ScaleX, ScaleY float32
+}
+
+// WindowEventHandler can be implemented by scenes that want window events.
+// Focus changes are also passed to Scene.SetFocused of every scene.
+type WindowEventHandler interface {
+	HandleWindowEvent(event WindowEvent)
+}
+
+// sendWindowEvent is called by the platform when the window changes. The platform callbacks run
+// in PollEvents on the main thread, like handleWindowEvents, so the events are queued without blocking.
+// Only the latest size and content scale are kept, so a live resize doesn't pile up events.
+func (w *Window) sendWindowEvent(event WindowEvent) {
+	if event.Type == WindowResized || event.Type == WindowContentScaleChanged {
+		for i := range w.windowEvents {
+			if w.windowEvents[i].Type == event.Type {
+				w.windowEvents[i] = event
+				return
+			}
+		}
+	}
+	w.windowEvents = append(w.windowEvents, event)
+}
+
+// handleWindowEvents applies the window events sent since the last frame
+func (w *Window) handleWindowEvents() {
+	// handling an event may queue new ones, they are handled next frame
+	events := w.windowEvents
+	w.windowEvents = nil
+	for _, event := range events {
+		w.handleWindowEvent(event)
+	}
+}
+
+func (w *Window) handleWindowEvent(event WindowEvent) {
+	switch event.Type {
+	case WindowFocused, WindowUnfocused:
+		w.setFocused(event.Type == WindowFocused)
+	case WindowIconified:
+		w.iconified = true
+	case WindowRestored:
+		w.iconified = false
+	case WindowContentScaleChanged:
+		w.contentScaleX, w.contentScaleY = event.ScaleX, event.ScaleY
+	}
+
+	for _, overlay := range w.visibleOverlays() {
+		if handler, ok := overlay.(WindowEventHandler); ok {
+			handler.HandleWindowEvent(event)
+		}
+	}
+	for _, scene := range w.activeScenes() {
+		if handler, ok := scene.(WindowEventHandler); ok {
+			handler.HandleWindowEvent(event)
+		}
+	}
+}
+
+// setFocused tells the scenes about the focus and pauses or resumes the
+// active scenes if pausing on focus loss is enabled
+func (w *Window) setFocused(focused bool) {
+	if focused == w.focused {
+		return
+	}
+	w.focused = focused
+	for _, scene := range w.orderedScenes() {
+		scene.SetFocused(focused)
+	}
+	for _, overlay := range w.orderedOverlays() {
+		overlay.SetFocused(focused)
+	}
+
+	if !focused && w.pauseOnFocusLoss {
+		for _, scene := range w.activeScenes() {
+			if scene.IsRunning() {
+				logSceneError(scene.Pause())
+				w.focusPausedScenes = append(w.focusPausedScenes, scene)
+			}
+		}
+	} else if focused {
+		// resume only the scenes paused by the focus loss that haven't changed state since
+		for _, scene := range w.focusPausedScenes {
+			if scene.IsPaused() {
+				logSceneError(scene.Resume())
+			}
+		}
+		w.focusPausedScenes = w.focusPausedScenes[:0]
+	}
+}
+
+// SetPauseOnFocusLoss makes the window pause its active scenes when it loses focus
+// and resume them when it gets it back. Paused scenes aren't ticked.
+func (w *Window) SetPauseOnFocusLoss(pause bool) {
+	w.pauseOnFocusLoss = pause
+}
+
+// IsFocused returns true if the window has input focus
+func (w *Window) IsFocused() bool {
+	return w.focused
+}
+
+// IsIconified returns true if the window is minimized
+func (w *Window) IsIconified() bool {
+	return w.iconified
+}
+
+// ContentScale returns the ratio of framebuffer pixels to window coordinates
+func (w *Window) ContentScale() (float32, float32) {
+	return w.contentScaleX, w.contentScaleY
+}
+
+// SetPauseOnFocusLoss makes the main window pause its active scenes when it loses focus
+func SetPauseOnFocusLoss(pause bool) {
+	MainWindow.SetPauseOnFocusLoss(pause)
+}
@@ -0,0 +1,96 @@
+package windows
+
+import (
+	"testing"
+)
+
+type windowEventTestScene struct {
+	stackTestScene
+	events []WindowEvent
+}
+
+func (s *windowEventTestScene) HandleWindowEvent(event WindowEvent) {
+	s.events = append(s.events, event)
+}
+
+func TestPauseOnFocusLoss(t *testing.T) {
+	headless := NewHeadlessPlatform()
+	SetPlatform(headless)
+	defer SetPlatform(glfwPlatform{})
+
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "headless")
+	defer func() { MainWindow = main }()
+	MainWindow.SetPauseOnFocusLoss(true)
+
+	scene := new(windowEventTestScene)
+	MainWindow.AddScene("test", scene)
+	var ticksWhenUnfocused int
+	headless.SetInputScript(MainWindow, func(frame int, input *HeadlessInput) {
+		switch frame {
+		case 2:
+			ticksWhenUnfocused = scene.ticks
+			input.Focus(false)
+		case 4:
+			if scene.IsFocused() || !scene.IsPaused() {
+				t.Errorf("the scene should be unfocused and paused")
+			}
+			if scene.ticks != ticksWhenUnfocused {
+				t.Errorf("a paused scene shouldn't tick, got %v ticks", scene.ticks-ticksWhenUnfocused)
+			}
+			input.Focus(true)
+			input.Resize(1024, 768)
+		case 5:
+			input.Close()
+		}
+	})
+	MainLoop()
+
+	expected := []WindowEvent{
+		{Type: WindowUnfocused},
+		{Type: WindowFocused},
+		{Type: WindowResized, Width: 1024, Height: 768},
+	}
+	if len(scene.events) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, scene.events)
+	}
+	for i := range expected {
+		if scene.events[i] != expected[i] {
+			t.Errorf("expected %v, got %v", expected[i], scene.events[i])
+		}
+	}
+	if scene.ticks != ticksWhenUnfocused+1 {
+		t.Errorf("the scene should tick again after getting the focus back")
+	}
+}
+
+func TestWindowEventsDontBlock(t *testing.T) {
+	w := NewWindow(800, 600, "test")
+	scene := new(windowEventTestScene)
+	w.AddScene("test", scene)
+	w.initScenes()
+
+	// a live resize sends many events between two frames
+	for i := 0; i < 50; i++ {
+		w.sendWindowEvent(WindowEvent{Type: WindowResized, Width: 800 + i, Height: 600})
+		w.sendWindowEvent(WindowEvent{Type: WindowContentScaleChanged, ScaleX: 1, ScaleY: 1})
+		w.sendWindowEvent(WindowEvent{Type: WindowFocused})
+	}
+	w.handleWindowEvents()
+
+	resizes, focuses := 0, 0
+	for _, event := range scene.events {
+		switch event.Type {
+		case WindowResized:
+			resizes++
+			if event.Width != 849 {
+				t.Errorf("expected the latest size, got %v", event.Width)
+			}
+		case WindowFocused:
+			focuses++
+		}
+	}
+	if resizes != 1 || focuses != 50 {
+		t.Errorf("expected one resize and all focus events, got %v and %v", resizes, focuses)
+	}
+}
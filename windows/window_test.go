@@ -40,3 +40,40 @@ func TestWindow(t *testing.T) {
 	}()
 	MainLoop()
 }
+
+// exitingTestScene exits the main loop after a number of ticks
+type exitingTestScene struct {
+	stackTestScene
+	exitAfter int
+}
+
+func (s *exitingTestScene) Tick(timedelta float64, keyStates []bool) {
+	s.stackTestScene.Tick(timedelta, keyStates)
+	if s.ticks == s.exitAfter {
+		Exit()
+	}
+}
+
+// Open a second window next to the main window and check that both tick
+// until the main loop ends.
+func TestSecondWindow(t *testing.T) {
+	main := MainWindow
+	MainWindow = NewWindow(800, 600, "First")
+	defer func() { MainWindow = main }()
+
+	first := &exitingTestScene{exitAfter: 5}
+	second := newStackTestScene(false)
+	Init()
+	MainWindow.AddScene("first", first)
+	other := NewWindow(320, 240, "Second")
+	other.Open()
+	other.AddScene("second", second)
+	MainLoop()
+
+	if first.ticks != 5 || second.ticks == 0 {
+		t.Errorf("both windows should tick, got %v and %v ticks", first.ticks, second.ticks)
+	}
+	if other.IsOpen() || !second.IsTerminated() {
+		t.Errorf("the second window should be closed with the main loop")
+	}
+}